@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"runtime"
 	"strconv"
+	"sync"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -26,6 +27,10 @@ func main() {
     compareMap()
     compareSet()
     compareDict()
+    compareHashFuncs()
+    compareBackends()
+    compareResizeLatency()
+    compareConcurrent()
 }
 
 func compareMap() {
@@ -284,6 +289,151 @@ func compareDict() {
     fmt.Printf("  Batch Delete (%d items): %v\n", numBatchOperations, quickdictBatchDeleteTime)
 }
 
+func compareHashFuncs() {
+    fmt.Println("\n--- Hash Function Comparison ---")
+
+    keys := make([]string, numOperations)
+    for i := 0; i < numOperations; i++ {
+        keys[i] = strconv.Itoa(i)
+    }
+
+    for _, name := range quickmap.HasherNames() {
+        start := time.Now()
+        qm := quickmap.New(quickmap.WithHashFunc(name))
+        for i, key := range keys {
+            qm.Insert(key, i)
+        }
+        insertTime := time.Since(start)
+
+        start = time.Now()
+        for _, key := range keys {
+            _, _ = qm.Get(key)
+        }
+        getTime := time.Since(start)
+
+        fmt.Printf("QuickMap (%s):\n", name)
+        fmt.Printf("  Insert: %v\n", insertTime)
+        fmt.Printf("  Get: %v\n", getTime)
+    }
+}
+
+func compareBackends() {
+    fmt.Println("\n--- Backend Comparison ---")
+
+    keys := make([]string, numOperations)
+    for i := 0; i < numOperations; i++ {
+        keys[i] = strconv.Itoa(i)
+    }
+
+    for _, backend := range []quickmap.Backend{quickmap.Chaining, quickmap.Swiss, quickmap.RobinHood} {
+        runtime.GC()
+        var before, after runtime.MemStats
+        runtime.ReadMemStats(&before)
+
+        start := time.Now()
+        qm := quickmap.NewMap[string, int](nil, quickmap.WithBackend(backend))
+        for i, key := range keys {
+            qm.Insert(key, i)
+        }
+        insertTime := time.Since(start)
+        runtime.ReadMemStats(&after)
+
+        start = time.Now()
+        for _, key := range keys {
+            _, _ = qm.Get(key)
+        }
+        getTime := time.Since(start)
+
+        start = time.Now()
+        for _, key := range keys {
+            qm.Delete(key)
+        }
+        deleteTime := time.Since(start)
+
+        fmt.Printf("QuickMap (%s):\n", backend)
+        fmt.Printf("  Insert: %v\n", insertTime)
+        fmt.Printf("  Get: %v\n", getTime)
+        fmt.Printf("  Delete: %v\n", deleteTime)
+        fmt.Printf("  Insert allocations: %d (%v MiB), GC cycles: %d\n",
+            after.Mallocs-before.Mallocs, bToMb(after.TotalAlloc-before.TotalAlloc), after.NumGC-before.NumGC)
+    }
+}
+
+func compareResizeLatency() {
+    fmt.Println("\n--- Resize Latency Comparison ---")
+
+    keys := make([]string, numOperations)
+    for i := 0; i < numOperations; i++ {
+        keys[i] = strconv.Itoa(i)
+    }
+
+    modes := []struct {
+        name string
+        opts []quickmap.Option
+    }{
+        {"single-step resize", nil},
+        {"incremental resize", []quickmap.Option{quickmap.WithIncrementalResize(0)}},
+    }
+    for _, mode := range modes {
+        qm := quickmap.NewMap[string, int](nil, mode.opts...)
+        var worst time.Duration
+        start := time.Now()
+        for i, key := range keys {
+            opStart := time.Now()
+            qm.Insert(key, i)
+            if d := time.Since(opStart); d > worst {
+                worst = d
+            }
+        }
+        total := time.Since(start)
+
+        fmt.Printf("QuickMap (%s):\n", mode.name)
+        fmt.Printf("  Insert: %v\n", total)
+        fmt.Printf("  Slowest single Insert: %v\n", worst)
+    }
+}
+
+func compareConcurrent() {
+    fmt.Println("\n--- Concurrent Read Comparison ---")
+
+    keys := make([]string, numOperations)
+    for i := 0; i < numOperations; i++ {
+        keys[i] = strconv.Itoa(i)
+    }
+    workers := runtime.GOMAXPROCS(0)
+
+    // readAll splits the keys across workers and times how long they take to look all of them up
+    readAll := func(get func(key string)) time.Duration {
+        var wg sync.WaitGroup
+        start := time.Now()
+        for w := 0; w < workers; w++ {
+            wg.Add(1)
+            go func(w int) {
+                defer wg.Done()
+                for i := w; i < len(keys); i += workers {
+                    get(keys[i])
+                }
+            }(w)
+        }
+        wg.Wait()
+        return time.Since(start)
+    }
+
+    var sm sync.Map
+    cm := quickmap.NewConcurrent()
+    am := quickmap.NewAtomic()
+    for i, key := range keys {
+        sm.Store(key, i)
+        cm.Insert(key, i)
+        am.Insert(key, i)
+    }
+
+    fmt.Printf("Get with %d goroutines:\n", workers)
+    fmt.Printf("  sync.Map: %v\n", readAll(func(key string) { sm.Load(key) }))
+    fmt.Printf("  ConcurrentQuickMap: %v\n", readAll(func(key string) { cm.Get(key) }))
+    fmt.Printf("  AtomicQuickMap: %v\n", readAll(func(key string) { am.Get(key) }))
+}
+
 func printMemUsage() {
     var m runtime.MemStats
     runtime.ReadMemStats(&m)
@@ -2,4 +2,4 @@ module github.com/marpit19/goquickmap
 
 go 1.23.1
 
-require github.com/deckarep/golang-set/v2 v2.6.0 // indirect
+require github.com/deckarep/golang-set/v2 v2.6.0
@@ -1,13 +1,116 @@
 package hash
 
-import "math/bits"
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/bits"
+)
 
 // hash computes a hash value for the given string
 func Hash(s string) uint64 {
-	var h uint64 = 14695981039346656037 // FNV-1 64 bit
+	return FNV(s, 0)
+}
+
+// FNV computes the FNV-1 hash of s with the seed folded into the offset basis.
+// It is fast on short keys but offers no protection against crafted collisions.
+func FNV(s string, seed uint64) uint64 {
+	var h uint64 = 14695981039346656037 ^ seed // FNV-1 64 bit
 	for i := 0; i < len(s); i++ {
 		h ^= uint64(s[i])
 		h *= 1099511628211 // FNV prime
 	}
 	return bits.RotateLeft64(h, 13)
 }
+
+// NewSeed returns a random seed read from crypto/rand
+func NewSeed() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("hash: reading random seed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// String computes a keyed hash value for the given string using SipHash-2-4.
+// Without knowing the seed, an attacker cannot construct colliding keys.
+func String(s string, seed uint64) uint64 {
+	k0, k1 := keys(seed)
+	return sip(k0, k1, s)
+}
+
+// Uint64 computes a keyed hash value for the given integer using SipHash-2-4
+// over its eight little-endian bytes
+func Uint64(x uint64, seed uint64) uint64 {
+	k0, k1 := keys(seed)
+	v0, v1, v2, v3 := initState(k0, k1)
+	v0, v1, v2, v3 = compress(v0, v1, v2, v3, x)
+	v0, v1, v2, v3 = compress(v0, v1, v2, v3, 8<<56)
+	return finalize(v0, v1, v2, v3)
+}
+
+// keys expands a 64-bit seed into the two 64-bit SipHash keys
+func keys(seed uint64) (uint64, uint64) {
+	k1 := seed + 0x9e3779b97f4a7c15
+	k1 = (k1 ^ (k1 >> 30)) * 0xbf58476d1ce4e5b9
+	k1 = (k1 ^ (k1 >> 27)) * 0x94d049bb133111eb
+	return seed, k1 ^ (k1 >> 31)
+}
+
+// sip computes SipHash-2-4 of s under the 128-bit key (k0, k1)
+func sip(k0, k1 uint64, s string) uint64 {
+	v0, v1, v2, v3 := initState(k0, k1)
+
+	n := len(s)
+	for len(s) >= 8 {
+		m := uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+			uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+		v0, v1, v2, v3 = compress(v0, v1, v2, v3, m)
+		s = s[8:]
+	}
+
+	b := uint64(n) << 56
+	for i := len(s) - 1; i >= 0; i-- {
+		b |= uint64(s[i]) << (8 * uint(i))
+	}
+	v0, v1, v2, v3 = compress(v0, v1, v2, v3, b)
+	return finalize(v0, v1, v2, v3)
+}
+
+func initState(k0, k1 uint64) (uint64, uint64, uint64, uint64) {
+	return k0 ^ 0x736f6d6570736575, k1 ^ 0x646f72616e646f6d,
+		k0 ^ 0x6c7967656e657261, k1 ^ 0x7465646279746573
+}
+
+func compress(v0, v1, v2, v3, m uint64) (uint64, uint64, uint64, uint64) {
+	v3 ^= m
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	v0 ^= m
+	return v0, v1, v2, v3
+}
+
+func finalize(v0, v1, v2, v3 uint64) uint64 {
+	v2 ^= 0xff
+	for i := 0; i < 4; i++ {
+		v0, v1, v2, v3 = round(v0, v1, v2, v3)
+	}
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+func round(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v1 = bits.RotateLeft64(v1, 13)
+	v1 ^= v0
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v3
+	v3 = bits.RotateLeft64(v3, 16)
+	v3 ^= v2
+	v0 += v3
+	v3 = bits.RotateLeft64(v3, 21)
+	v3 ^= v0
+	v2 += v1
+	v1 = bits.RotateLeft64(v1, 17)
+	v1 ^= v2
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
@@ -0,0 +1,64 @@
+package hash
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestSipHash(t *testing.T) {
+	// Reference vectors from the SipHash paper: key 00..0f, message 00..n-1
+	k0, k1 := uint64(0x0706050403020100), uint64(0x0f0e0d0c0b0a0908)
+	message := make([]byte, 15)
+	for i := range message {
+		message[i] = byte(i)
+	}
+	vectors := map[int]uint64{
+		0:  0x726fdb47dd0e0e31,
+		1:  0x74f839c593dc67fd,
+		8:  0x93f5f5799a932462,
+		15: 0xa129ca6149be45e5,
+	}
+	for n, expected := range vectors {
+		if got := sip(k0, k1, string(message[:n])); got != expected {
+			t.Errorf("sip(%d bytes) = %#x, expected %#x", n, got, expected)
+		}
+	}
+
+	t.Run("Uint64 matches String", func(t *testing.T) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], 0x0706050403020100)
+		if Uint64(0x0706050403020100, 7) != String(string(b[:]), 7) {
+			t.Errorf("Uint64(x, 7) != String(bytes(x), 7)")
+		}
+	})
+
+	t.Run("Hash is unseeded FNV", func(t *testing.T) {
+		if Hash("key") != FNV("key", 0) {
+			t.Errorf("Hash(\"key\") != FNV(\"key\", 0)")
+		}
+	})
+
+	t.Run("Seed changes hash", func(t *testing.T) {
+		if String("key", 1) == String("key", 2) {
+			t.Errorf("String(\"key\", 1) == String(\"key\", 2), expected different hashes")
+		}
+	})
+}
+
+func TestWy(t *testing.T) {
+	// Exercise every length class: empty, 1-3, 4-16, 17-47 and 48+ bytes
+	seen := make(map[uint64]int)
+	for n := 0; n <= 100; n++ {
+		h := Wy(string(make([]byte, n)), 1)
+		if prev, dup := seen[h]; dup {
+			t.Errorf("Wy(%d zero bytes) == Wy(%d zero bytes)", n, prev)
+		}
+		seen[h] = n
+	}
+	if Wy("key", 1) == Wy("key", 2) {
+		t.Errorf("Wy(\"key\", 1) == Wy(\"key\", 2), expected different hashes")
+	}
+	if Wy("key", 1) != Wy("key", 1) {
+		t.Errorf("Wy(\"key\", 1) is not deterministic")
+	}
+}
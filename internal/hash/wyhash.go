@@ -0,0 +1,71 @@
+package hash
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+var wyp = [4]uint64{0x2d358dccaa6c78a5, 0x8bb84b93962eacc9, 0x4b33a62ed433d4a3, 0x4d5a2da51de1aa47}
+
+// Wy computes a wyhash-style hash of s. It reads the input eight bytes at a
+// time and folds it with 128-bit multiplies, which makes it considerably faster
+// than SipHash on long keys.
+func Wy(s string, seed uint64) uint64 {
+	seed ^= wymix(seed^wyp[0], wyp[1])
+	n := len(s)
+	var a, b uint64
+	if n <= 16 {
+		if n >= 4 {
+			q := (n >> 3) << 2
+			a = read4(s)<<32 | read4(s[q:])
+			b = read4(s[n-4:])<<32 | read4(s[n-4-q:])
+		} else if n > 0 {
+			a = uint64(s[0])<<16 | uint64(s[n>>1])<<8 | uint64(s[n-1])
+		}
+	} else {
+		p := s
+		if len(p) >= 48 {
+			see1, see2 := seed, seed
+			for len(p) >= 48 {
+				seed = wymix(read8(p)^wyp[1], read8(p[8:])^seed)
+				see1 = wymix(read8(p[16:])^wyp[2], read8(p[24:])^see1)
+				see2 = wymix(read8(p[32:])^wyp[3], read8(p[40:])^see2)
+				p = p[48:]
+			}
+			seed ^= see1 ^ see2
+		}
+		for len(p) > 16 {
+			seed = wymix(read8(p)^wyp[1], read8(p[8:])^seed)
+			p = p[16:]
+		}
+		a = read8(s[n-16:])
+		b = read8(s[n-8:])
+	}
+	a ^= wyp[1]
+	b ^= seed
+	hi, lo := bits.Mul64(a, b)
+	return wymix(lo^wyp[0]^uint64(n), hi^wyp[1])
+}
+
+func wymix(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func read8(s string) uint64 {
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+		uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+}
+
+func read4(s string) uint64 {
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24
+}
+
+var mapSeed = maphash.MakeSeed()
+
+// Map computes a hash of s with hash/maphash. The runtime draws the underlying
+// maphash seed once per process, so results are not reproducible across
+// processes even when the seed argument is fixed.
+func Map(s string, seed uint64) uint64 {
+	return maphash.String(mapSeed, s) ^ seed
+}
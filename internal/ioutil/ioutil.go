@@ -0,0 +1,16 @@
+// Package ioutil holds the io helpers shared by the package's encoders
+package ioutil
+
+import "io"
+
+// CountingWriter counts the bytes that reach W in N
+type CountingWriter struct {
+	W io.Writer
+	N int64
+}
+
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.W.Write(p)
+	c.N += int64(n)
+	return n, err
+}
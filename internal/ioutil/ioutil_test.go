@@ -0,0 +1,38 @@
+package ioutil
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// shortWriter accepts a limited number of bytes, then fails
+type shortWriter struct {
+	room int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.room {
+		n := w.room
+		w.room = 0
+		return n, errors.New("short write")
+	}
+	w.room -= len(p)
+	return len(p), nil
+}
+
+func TestCountingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	c := &CountingWriter{W: &buf}
+	c.Write([]byte("hello, "))
+	c.Write([]byte("world"))
+	if c.N != 12 || buf.String() != "hello, world" {
+		t.Errorf("N = %d with %q written, expected 12 and \"hello, world\"", c.N, buf.String())
+	}
+
+	// Only the bytes the underlying writer accepts are counted
+	c = &CountingWriter{W: &shortWriter{room: 3}}
+	if n, err := c.Write([]byte("hello")); n != 3 || err == nil || c.N != 3 {
+		t.Errorf("Write = %d, %v with N = %d; expected 3, an error and 3", n, err, c.N)
+	}
+}
@@ -0,0 +1,224 @@
+package jsonenc
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EncodeKey returns the JSON object key for key, following encoding/json's
+// rules for map keys: string kinds are used as they are, then
+// encoding.TextMarshaler is used, then integer kinds are formatted in decimal
+func EncodeKey(key any) (string, error) {
+	v := reflect.ValueOf(key)
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("jsonenc: unsupported key type %T", key)
+}
+
+// DecodeKey parses a JSON object key produced by EncodeKey
+func DecodeKey[K any](s string) (K, error) {
+	var key K
+	if tu, ok := any(&key).(encoding.TextUnmarshaler); ok {
+		err := tu.UnmarshalText([]byte(s))
+		return key, err
+	}
+	v := reflect.ValueOf(&key).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return key, fmt.Errorf("jsonenc: invalid %T key %q", key, s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return key, fmt.Errorf("jsonenc: invalid %T key %q", key, s)
+		}
+		v.SetUint(n)
+	default:
+		return key, fmt.Errorf("jsonenc: unsupported key type %T", key)
+	}
+	return key, nil
+}
+
+// EncodeObject encodes the pairs of all as a JSON object. With sorted set,
+// members are ordered by key as encoding/json orders map keys.
+func EncodeObject[K comparable, V any](all iter.Seq2[K, V], sorted bool) ([]byte, error) {
+	type member struct {
+		key   string
+		value []byte
+	}
+	var members []member
+	for k, v := range all {
+		key, err := EncodeKey(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member{key, value})
+	}
+	if sorted {
+		slices.SortFunc(members, func(a, b member) int {
+			return strings.Compare(a.key, b.key)
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// EncodeArray encodes the elements of all as a JSON array. With sorted set,
+// strings and numbers are ordered by value and other elements by their
+// encoding.
+func EncodeArray[T any](all iter.Seq[T], sorted bool) ([]byte, error) {
+	type element struct {
+		value   T
+		encoded []byte
+	}
+	var elements []element
+	for v := range all {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element{v, encoded})
+	}
+	if sorted {
+		slices.SortFunc(elements, func(a, b element) int {
+			if c, ok := compareOrdered(reflect.ValueOf(a.value), reflect.ValueOf(b.value)); ok {
+				return c
+			}
+			return bytes.Compare(a.encoded, b.encoded)
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, e := range elements {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(e.encoded)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// compareOrdered compares a and b by value if they are strings or numbers
+func compareOrdered(a, b reflect.Value) (int, bool) {
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmpValues(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmpValues(a.Uint(), b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return cmpValues(a.Float(), b.Float()), true
+	}
+	return 0, false
+}
+
+func cmpValues[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// DecodeObject reads a JSON object from dec one member at a time, calling
+// set with each decoded key and value. A JSON null is accepted and sets
+// nothing.
+func DecodeObject[K comparable, V any](dec *json.Decoder, set func(key K, value V)) error {
+	if ok, err := begin(dec, '{'); !ok {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, err := DecodeKey[K](token.(string))
+		if err != nil {
+			return err
+		}
+		var value V
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		set(key, value)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// DecodeArray reads a JSON array from dec one element at a time, calling add
+// with each decoded element. A JSON null is accepted and adds nothing.
+func DecodeArray[T any](dec *json.Decoder, add func(element T)) error {
+	if ok, err := begin(dec, '['); !ok {
+		return err
+	}
+	for dec.More() {
+		var element T
+		if err := dec.Decode(&element); err != nil {
+			return err
+		}
+		add(element)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+// begin reads the opening delimiter of an object or array. It returns false
+// with a nil error for a JSON null.
+func begin(dec *json.Decoder, delim json.Delim) (bool, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if token == nil {
+		return false, nil
+	}
+	if token != delim {
+		return false, fmt.Errorf("jsonenc: expected %v, found %v", delim, token)
+	}
+	return true, nil
+}
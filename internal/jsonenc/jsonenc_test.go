@@ -0,0 +1,38 @@
+package jsonenc
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	type id int8
+	addr := netip.MustParseAddr("10.0.0.1")
+	cases := []struct {
+		key      any
+		expected string
+	}{
+		{"text", "text"},
+		{id(-7), "-7"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{addr, "10.0.0.1"},
+	}
+	for _, c := range cases {
+		if got, err := EncodeKey(c.key); err != nil || got != c.expected {
+			t.Errorf("EncodeKey(%v) = %q, %v; expected %q, nil", c.key, got, err, c.expected)
+		}
+	}
+	if _, err := EncodeKey(1.5); err == nil {
+		t.Errorf("EncodeKey(1.5) returned nil, expected an error")
+	}
+
+	if key, err := DecodeKey[id]("-7"); err != nil || key != -7 {
+		t.Errorf("DecodeKey[id](\"-7\") = %d, %v; expected -7, nil", key, err)
+	}
+	if _, err := DecodeKey[id]("300"); err == nil {
+		t.Errorf("DecodeKey[id](\"300\") returned nil, expected an overflow error")
+	}
+	if key, err := DecodeKey[netip.Addr]("10.0.0.1"); err != nil || key != addr {
+		t.Errorf("DecodeKey[netip.Addr] = %v, %v; expected %v, nil", key, err, addr)
+	}
+}
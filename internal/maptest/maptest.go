@@ -0,0 +1,198 @@
+// Package maptest checks the package's maps against Go's built-in map. It
+// decodes a byte stream, usually from a fuzz target, into a sequence of
+// operations, applies each one to both and compares the results.
+package maptest
+
+import "fmt"
+
+// Map is the view of a map under test that the harness drives. Keys and
+// values are decoded from single bytes, so that short inputs keep returning
+// to the same keys.
+type Map interface {
+	Insert(key, value int)
+	Get(key int) (int, bool)
+	Delete(key int)
+	InsertMany(pairs map[int]int)
+	DeleteMany(keys []int)
+	ForEach(f func(key, value int))
+	Size() int
+}
+
+// Validator is implemented by maps that can check their own invariants.
+// Check calls Validate after every operation on such maps.
+type Validator interface {
+	Validate() error
+}
+
+// The operations an input byte selects, taken modulo opCount. Each is
+// followed by its operands:
+//
+//	opInsert:     key | value
+//	opGet:        key
+//	opDelete:     key
+//	opInsertMany: count | count × (key | value)
+//	opDeleteMany: count | count × key
+//	opForEach:    nothing
+//
+// Counts are taken modulo maxBatch. An input that ends partway through an
+// operation ends before it.
+const (
+	opInsert = iota
+	opGet
+	opDelete
+	opInsertMany
+	opDeleteMany
+	opForEach
+	opCount
+
+	maxBatch = 16
+)
+
+// Check applies the operations encoded in data to m, which must start empty,
+// and to a built-in map, and returns an error describing the first step at
+// which they disagree.
+func Check(data []byte, m Map) error {
+	return check(data, m, true)
+}
+
+// CheckSet is Check for sets, which m adapts as a Map whose values are all
+// 0. Every value decoded from data is replaced with 0.
+func CheckSet(data []byte, m Map) error {
+	return check(data, m, false)
+}
+
+func check(data []byte, m Map, values bool) error {
+	r := reader{data: data}
+	model := make(map[int]int)
+	value := func() int {
+		v := r.next()
+		if !values {
+			return 0
+		}
+		return v
+	}
+
+	for step := 1; ; step++ {
+		if r.done() {
+			return compare(m, model, step)
+		}
+		op := r.next() % opCount
+		var desc string
+		switch op {
+		case opInsert:
+			key, v := r.next(), value()
+			if r.short {
+				return nil
+			}
+			m.Insert(key, v)
+			model[key] = v
+			desc = fmt.Sprintf("Insert(%d, %d)", key, v)
+		case opGet:
+			key := r.next()
+			if r.short {
+				return nil
+			}
+			got, ok := m.Get(key)
+			expected, exists := model[key]
+			if got != expected || ok != exists {
+				return fmt.Errorf("maptest: step %d: Get(%d) = %d, %t; expected %d, %t", step, key, got, ok, expected, exists)
+			}
+			desc = fmt.Sprintf("Get(%d)", key)
+		case opDelete:
+			key := r.next()
+			if r.short {
+				return nil
+			}
+			m.Delete(key)
+			delete(model, key)
+			desc = fmt.Sprintf("Delete(%d)", key)
+		case opInsertMany:
+			pairs := make(map[int]int)
+			for n := r.next() % maxBatch; n > 0; n-- {
+				key, v := r.next(), value()
+				pairs[key] = v
+			}
+			if r.short {
+				return nil
+			}
+			m.InsertMany(pairs)
+			for key, v := range pairs {
+				model[key] = v
+			}
+			desc = fmt.Sprintf("InsertMany(%v)", pairs)
+		case opDeleteMany:
+			var keys []int
+			for n := r.next() % maxBatch; n > 0; n-- {
+				keys = append(keys, r.next())
+			}
+			if r.short {
+				return nil
+			}
+			m.DeleteMany(keys)
+			for _, key := range keys {
+				delete(model, key)
+			}
+			desc = fmt.Sprintf("DeleteMany(%v)", keys)
+		case opForEach:
+			if err := compare(m, model, step); err != nil {
+				return err
+			}
+			desc = "ForEach"
+		}
+
+		if m.Size() != len(model) {
+			return fmt.Errorf("maptest: step %d: Size() = %d after %s, expected %d", step, m.Size(), desc, len(model))
+		}
+		if v, ok := m.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return fmt.Errorf("maptest: step %d: Validate() after %s: %w", step, desc, err)
+			}
+		}
+	}
+}
+
+// compare checks that ForEach visits exactly the entries of model, once each
+func compare(m Map, model map[int]int, step int) error {
+	seen := make(map[int]bool, len(model))
+	var err error
+	m.ForEach(func(key, value int) {
+		if err != nil {
+			return
+		}
+		expected, exists := model[key]
+		switch {
+		case seen[key]:
+			err = fmt.Errorf("maptest: step %d: ForEach visited %d twice", step, key)
+		case !exists:
+			err = fmt.Errorf("maptest: step %d: ForEach visited %d, which is not in the map", step, key)
+		case value != expected:
+			err = fmt.Errorf("maptest: step %d: ForEach visited %d with %d, expected %d", step, key, value, expected)
+		}
+		seen[key] = true
+	})
+	if err == nil && len(seen) != len(model) {
+		err = fmt.Errorf("maptest: step %d: ForEach visited %d keys, expected %d", step, len(seen), len(model))
+	}
+	return err
+}
+
+// reader hands out the bytes of an input as ints, recording whether it ran
+// out
+type reader struct {
+	data  []byte
+	short bool
+}
+
+func (r *reader) done() bool {
+	return len(r.data) == 0
+}
+
+func (r *reader) next() int {
+	if len(r.data) == 0 {
+		r.short = true
+		return 0
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return int(b)
+}
@@ -0,0 +1,88 @@
+package maptest
+
+import (
+	"strings"
+	"testing"
+)
+
+// builtin adapts a built-in map, optionally miscounting overwrites
+type builtin struct {
+	m         map[int]int
+	countsAll bool
+	inserts   int
+}
+
+func (b *builtin) Insert(key, value int) {
+	b.m[key] = value
+	b.inserts++
+}
+
+func (b *builtin) Get(key int) (int, bool) {
+	value, ok := b.m[key]
+	return value, ok
+}
+
+func (b *builtin) Delete(key int) {
+	if _, ok := b.m[key]; ok {
+		b.inserts--
+	}
+	delete(b.m, key)
+}
+
+func (b *builtin) InsertMany(pairs map[int]int) {
+	for key, value := range pairs {
+		b.Insert(key, value)
+	}
+}
+
+func (b *builtin) DeleteMany(keys []int) {
+	for _, key := range keys {
+		b.Delete(key)
+	}
+}
+
+func (b *builtin) ForEach(f func(key, value int)) {
+	for key, value := range b.m {
+		f(key, value)
+	}
+}
+
+func (b *builtin) Size() int {
+	if b.countsAll {
+		return b.inserts
+	}
+	return len(b.m)
+}
+
+func TestCheck(t *testing.T) {
+	data := []byte{
+		opInsert, 1, 10,
+		opInsert, 2, 20,
+		opGet, 1,
+		opInsertMany, 2, 3, 30, 1, 11,
+		opForEach,
+		opDeleteMany, 2, 2, 9,
+		opDelete, 3,
+		opForEach,
+	}
+
+	// Test that a correct map passes, as does every prefix of the input
+	t.Run("Built-in map", func(t *testing.T) {
+		for i := range data {
+			if err := Check(data[:i], &builtin{m: make(map[int]int)}); err != nil {
+				t.Errorf("Check(data[:%d]) = %v, expected nil", i, err)
+			}
+		}
+		if err := CheckSet([]byte{opInsert, 1, 10, opGet, 1, opForEach}, &builtin{m: make(map[int]int)}); err != nil {
+			t.Errorf("CheckSet = %v, expected nil", err)
+		}
+	})
+
+	// Test that a size that counts overwrites is caught at the overwrite
+	t.Run("Size drift", func(t *testing.T) {
+		err := Check(data, &builtin{m: make(map[int]int), countsAll: true})
+		if err == nil || !strings.Contains(err.Error(), "step 4: Size() = 4") {
+			t.Errorf("Check = %v, expected a size mismatch at step 4", err)
+		}
+	})
+}
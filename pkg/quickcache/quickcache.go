@@ -0,0 +1,249 @@
+package quickcache
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// Cache is a bounded LRU cache that is safe for concurrent use. Get and Put
+// take O(1) time and make the key the most recently used. Once the entries'
+// total cost exceeds the capacity, the least recently used ones are evicted.
+// By default every entry costs 1, so the capacity is a number of entries;
+// WithCost supplies a cost function instead.
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	entries  *quickmap.LinkedQuickMap[K, entry[V]] // least recently used first
+	capacity int64
+	cost     int64 // total cost of all entries
+	costFunc func(key K, value V) int64
+	onEvict  func(key K, value V)
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+type entry[V any] struct {
+	value V
+	cost  int64
+}
+
+// StringCache is a Cache with string keys and untyped values, as returned by New
+type StringCache = Cache[string, interface{}]
+
+// Stats holds a cache's counters
+type Stats struct {
+	Hits      uint64 // Get calls that found their key
+	Misses    uint64 // Get calls that did not
+	Evictions uint64 // entries removed to stay within the capacity
+}
+
+// Option configures a Cache
+type Option func(*options)
+
+type options struct {
+	costFunc any
+	onEvict  any
+	mapOpts  []quickmap.Option
+}
+
+// WithCost sets the function that gives the cost of each entry as it is
+// put. The capacity then bounds the total cost rather than the number of
+// entries. Costs must not be negative. The function's key and value types
+// must match the cache's, or the constructor panics.
+func WithCost[K comparable, V any](cost func(key K, value V) int64) Option {
+	return func(o *options) {
+		o.costFunc = cost
+	}
+}
+
+// WithEvictionCallback sets a function to call with every entry evicted to
+// stay within the capacity. It is called after the cache's lock is released,
+// so it may use the cache. The function's key and value types must match the
+// cache's, or the constructor panics.
+func WithEvictionCallback[K comparable, V any](onEvict func(key K, value V)) Option {
+	return func(o *options) {
+		o.onEvict = onEvict
+	}
+}
+
+// WithMapOptions passes options such as quickmap.WithSeed or
+// quickmap.WithHashFunc to the underlying map
+func WithMapOptions(opts ...quickmap.Option) Option {
+	return func(o *options) {
+		o.mapOpts = append(o.mapOpts, opts...)
+	}
+}
+
+// New creates and returns a new Cache with string keys that holds entries up to the given capacity
+func New(capacity int64, opts ...Option) *StringCache {
+	return NewCache[string, interface{}](nil, capacity, opts...)
+}
+
+// NewCache creates and returns a new Cache that hashes its keys with hasher
+// and holds entries up to the given capacity. A nil hasher behaves as
+// described for quickmap.NewMap. It panics if capacity is not positive.
+func NewCache[K comparable, V any](hasher quickmap.Hasher[K], capacity int64, opts ...Option) *Cache[K, V] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("quickcache: capacity %d is not positive", capacity))
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Cache[K, V]{
+		entries:  quickmap.NewLinkedMap[K, entry[V]](hasher, o.mapOpts...),
+		capacity: capacity,
+		costFunc: func(K, V) int64 { return 1 },
+	}
+	if o.costFunc != nil {
+		f, ok := o.costFunc.(func(K, V) int64)
+		if !ok {
+			panic(fmt.Sprintf("quickcache: WithCost function %T does not match the cache's key and value types", o.costFunc))
+		}
+		c.costFunc = f
+	}
+	if o.onEvict != nil {
+		f, ok := o.onEvict.(func(K, V))
+		if !ok {
+			panic(fmt.Sprintf("quickcache: WithEvictionCallback function %T does not match the cache's key and value types", o.onEvict))
+		}
+		c.onEvict = f
+	}
+	return c
+}
+
+// Get retrieves a value by key and marks the key as most recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	e, ok := c.entries.Get(key)
+	if ok {
+		c.entries.MoveToBack(key)
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return e.value, ok
+}
+
+// Peek retrieves a value by key without changing its recency or the counters
+func (c *Cache[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries.Get(key)
+	return e.value, ok
+}
+
+// Put inserts or updates a key-value pair, marks the key as most recently
+// used, and evicts least recently used entries until the total cost is within
+// the capacity. An entry that costs more than the whole capacity is evicted
+// straight away, without evicting any other entry; any previous value for
+// the key is removed.
+func (c *Cache[K, V]) Put(key K, value V) {
+	e := entry[V]{value: value, cost: c.costFunc(key, value)}
+	c.mu.Lock()
+	if e.cost > c.capacity {
+		if previous, loaded := c.entries.LoadAndDelete(key); loaded {
+			c.cost -= previous.cost
+		}
+		c.mu.Unlock()
+		c.evictions.Add(1)
+		if c.onEvict != nil {
+			c.onEvict(key, value)
+		}
+		return
+	}
+	if previous, loaded := c.entries.Swap(key, e); loaded {
+		c.cost -= previous.cost
+		c.entries.MoveToBack(key)
+	}
+	c.cost += e.cost
+	evicted := c.evictLocked()
+	c.mu.Unlock()
+
+	for _, kv := range evicted {
+		c.onEvict(kv.key, kv.value)
+	}
+}
+
+type evictedEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// evictLocked removes least recently used entries until the cache is within
+// its capacity. It returns them if there is an eviction callback to call.
+func (c *Cache[K, V]) evictLocked() []evictedEntry[K, V] {
+	var evicted []evictedEntry[K, V]
+	for c.cost > c.capacity {
+		key, e, ok := c.entries.PopFirst()
+		if !ok {
+			break
+		}
+		c.cost -= e.cost
+		c.evictions.Add(1)
+		if c.onEvict != nil {
+			evicted = append(evicted, evictedEntry[K, V]{key, e.value})
+		}
+	}
+	return evicted
+}
+
+// Delete removes a key from the cache and reports whether it was present.
+// The eviction callback is not called.
+func (c *Cache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, loaded := c.entries.LoadAndDelete(key)
+	if loaded {
+		c.cost -= e.cost
+	}
+	return loaded
+}
+
+// Len returns the number of entries in the cache
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Size()
+}
+
+// Cost returns the total cost of the entries in the cache, which equals Len
+// unless WithCost is used
+func (c *Cache[K, V]) Cost() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cost
+}
+
+// Capacity returns the maximum total cost of the entries in the cache
+func (c *Cache[K, V]) Capacity() int64 {
+	return c.capacity
+}
+
+// Purge removes every entry without calling the eviction callback. The
+// counters are left as they are.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries.DeleteFunc(func(K, entry[V]) bool { return true })
+	c.entries.Compact()
+	c.cost = 0
+}
+
+// Stats returns the cache's hit, miss and eviction counters
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
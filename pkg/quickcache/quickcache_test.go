@@ -0,0 +1,148 @@
+package quickcache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+func TestCache(t *testing.T) {
+	// Test recency and eviction by entry count
+	t.Run("LRU eviction", func(t *testing.T) {
+		var evicted []string
+		c := New(3, WithEvictionCallback(func(key string, value interface{}) {
+			evicted = append(evicted, key)
+		}))
+		c.Put("a", 1)
+		c.Put("b", 2)
+		c.Put("c", 3)
+		c.Get("a")     // b is now least recently used
+		c.Put("c", 30) // updating refreshes c as well
+		c.Put("d", 4)
+		if len(evicted) != 1 || evicted[0] != "b" {
+			t.Errorf("Evicted %v, expected [b]", evicted)
+		}
+		if _, ok := c.Peek("b"); ok {
+			t.Errorf("Peek(\"b\") returned true after eviction, expected false")
+		}
+		c.Peek("a") // does not refresh a
+		c.Put("e", 5)
+		if len(evicted) != 2 || evicted[1] != "a" {
+			t.Errorf("Evicted %v, expected [b a]", evicted)
+		}
+		if value, ok := c.Get("c"); !ok || value != 30 {
+			t.Errorf("Get(\"c\") = %v, %t; expected 30, true", value, ok)
+		}
+		if c.Len() != 3 || c.Cost() != 3 {
+			t.Errorf("Len() = %d, Cost() = %d; expected 3 and 3", c.Len(), c.Cost())
+		}
+	})
+
+	// Test capacity by cost
+	t.Run("Cost", func(t *testing.T) {
+		c := NewCache[string, []byte](nil, 10, WithCost(func(key string, value []byte) int64 {
+			return int64(len(value))
+		}))
+		c.Put("a", make([]byte, 4))
+		c.Put("b", make([]byte, 4))
+		c.Put("a", make([]byte, 2))
+		if c.Cost() != 6 {
+			t.Errorf("Cost() = %d after replacing a value, expected 6", c.Cost())
+		}
+		c.Put("c", make([]byte, 5))
+		if _, ok := c.Peek("b"); ok || c.Cost() != 7 {
+			t.Errorf("Cost() = %d with b present = %t; expected 7 and false", c.Cost(), ok)
+		}
+		c.Put("huge", make([]byte, 11))
+		if c.Len() != 2 || c.Cost() != 7 {
+			t.Errorf("Len() = %d, Cost() = %d after an oversized Put; expected 2 and 7", c.Len(), c.Cost())
+		}
+		if _, ok := c.Peek("huge"); ok {
+			t.Errorf("Peek(\"huge\") found the oversized entry, expected it evicted")
+		}
+		if stats := c.Stats(); stats.Evictions != 2 {
+			t.Errorf("Stats().Evictions = %d, expected 2", stats.Evictions)
+		}
+		c.Put("a", make([]byte, 11))
+		if _, ok := c.Peek("a"); ok || c.Len() != 1 || c.Cost() != 5 {
+			t.Errorf("Len() = %d, Cost() = %d with a present = %t after an oversized Put of a; expected 1, 5 and false", c.Len(), c.Cost(), ok)
+		}
+	})
+
+	// Test counters, Delete and Purge
+	t.Run("Stats", func(t *testing.T) {
+		c := NewCache[int, int](nil, 100, WithMapOptions(quickmap.WithSeed(1)))
+		for i := 0; i < 10; i++ {
+			c.Put(i, i)
+		}
+		for i := 0; i < 20; i++ {
+			c.Get(i)
+		}
+		if stats := c.Stats(); stats != (Stats{Hits: 10, Misses: 10}) {
+			t.Errorf("Stats() = %+v, expected 10 hits and 10 misses", stats)
+		}
+		if !c.Delete(5) || c.Delete(5) || c.Cost() != 9 {
+			t.Errorf("Delete(5) did not remove exactly one entry")
+		}
+		c.Purge()
+		if c.Len() != 0 || c.Cost() != 0 || c.Stats().Hits != 10 {
+			t.Errorf("Purge() left Len() = %d, Cost() = %d", c.Len(), c.Cost())
+		}
+	})
+
+	// Test invalid configuration
+	t.Run("Invalid options", func(t *testing.T) {
+		for name, f := range map[string]func(){
+			"capacity": func() { New(0) },
+			"cost":     func() { NewCache[int, int](nil, 1, WithCost(func(string, int) int64 { return 1 })) },
+			"callback": func() { NewCache[int, int](nil, 1, WithEvictionCallback(func(int, string) {})) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s: expected a panic", name)
+					}
+				}()
+				f()
+			}()
+		}
+	})
+
+	// Test concurrent use, including from the eviction callback; run with -race
+	t.Run("Parallel", func(t *testing.T) {
+		var c *Cache[string, int]
+		c = NewCache[string, int](nil, 100, WithEvictionCallback(func(key string, value int) {
+			c.Peek(key)
+		}))
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					key := strconv.Itoa(g*100 + i%200)
+					if _, ok := c.Get(key); !ok {
+						c.Put(key, i)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		stats := c.Stats()
+		if c.Len() != 100 || stats.Hits+stats.Misses != 8000 {
+			t.Errorf("Len() = %d, Stats() = %+v; expected 100 entries and 8000 lookups", c.Len(), stats)
+		}
+	})
+}
+
+func BenchmarkCache(b *testing.B) {
+	c := NewCache[int, int](nil, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := c.Get(i % 2000); !ok {
+			c.Put(i%2000, i)
+		}
+	}
+}
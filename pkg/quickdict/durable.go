@@ -0,0 +1,446 @@
+package quickdict
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+const (
+	logFile      = "wal"
+	snapshotFile = "snapshot"
+
+	defaultSyncInterval = time.Second
+	defaultCompactAfter = 100000
+)
+
+// SyncPolicy selects when a DurableQuickDict syncs its log to stable storage
+type SyncPolicy int
+
+const (
+	// SyncAlways syncs after every write, so a write that returned survives
+	// a power failure
+	SyncAlways SyncPolicy = iota
+	// SyncPeriodically syncs in the background at the WithSyncInterval
+	// interval, which defaults to one second. A crash of the process loses
+	// nothing, but a power failure can lose the writes of the last interval.
+	SyncPeriodically
+	// SyncNever leaves syncing to the operating system
+	SyncNever
+)
+
+// DurableOption configures a DurableQuickDict
+type DurableOption func(*durableOptions)
+
+type durableOptions struct {
+	sync         SyncPolicy
+	syncInterval time.Duration
+	compactAfter int
+	mapOpts      []quickmap.Option
+}
+
+// WithSyncPolicy selects when the log is synced. The default is SyncAlways.
+func WithSyncPolicy(policy SyncPolicy) DurableOption {
+	return func(o *durableOptions) {
+		o.sync = policy
+	}
+}
+
+// WithSyncInterval selects SyncPeriodically with the given interval
+func WithSyncInterval(interval time.Duration) DurableOption {
+	return func(o *durableOptions) {
+		o.sync = SyncPeriodically
+		o.syncInterval = interval
+	}
+}
+
+// WithCompactAfter sets how many records the log may hold before it is
+// compacted into a snapshot. Compaction also waits until the log holds more
+// records than the dictionary has entries, spreading the cost of writing the
+// snapshot over at least as many writes. The default is 100000; n below 1
+// disables automatic compaction, leaving it to Checkpoint.
+func WithCompactAfter(n int) DurableOption {
+	return func(o *durableOptions) {
+		o.compactAfter = n
+	}
+}
+
+// WithMapOptions passes options such as quickmap.WithBackend or
+// quickmap.WithValueCodec to the underlying map. Its key and value codecs are
+// used for the log as well as for snapshots.
+func WithMapOptions(opts ...quickmap.Option) DurableOption {
+	return func(o *durableOptions) {
+		o.mapOpts = append(o.mapOpts, opts...)
+	}
+}
+
+// DurableQuickDict is a QuickDict whose writes are persisted to a directory.
+// Every Set, Delete, SetMany and DeleteMany is appended to a write-ahead log
+// before it is applied, and OpenDurable rebuilds the dictionary by loading
+// the latest snapshot and replaying the log. The log is compacted into a new
+// snapshot once it grows past the WithCompactAfter threshold.
+//
+// A DurableQuickDict is safe for concurrent use, but the directory must only
+// be opened by one DurableQuickDict at a time.
+type DurableQuickDict[K comparable, V any] struct {
+	mu      sync.Mutex
+	dict    *QuickDict[K, V]
+	dir     string
+	log     *os.File
+	enc     recordEncoder[K, V]
+	opts    durableOptions
+	records int
+	// size is the length of the log up to the last complete record
+	size int64
+	// dirty is set when the log has writes that have not been synced
+	dirty bool
+	// err is set when the log can no longer be trusted, and is returned by
+	// every later write, Sync and Close
+	err    error
+	syncer *janitor
+}
+
+// errDurableClosed is returned by the methods of a closed DurableQuickDict
+var errDurableClosed = errors.New("quickdict: durable dictionary is closed")
+
+// OpenDurable opens the durable dictionary stored in dir, creating the
+// directory if it does not exist. A nil hasher behaves as described for
+// quickmap.NewMap. A record torn by a crash at the end of the log is
+// discarded. A damaged record with more of the log after it is reported as
+// an error, and the log is left as it is.
+func OpenDurable[K comparable, V any](dir string, hasher quickmap.Hasher[K], opts ...DurableOption) (*DurableQuickDict[K, V], error) {
+	o := durableOptions{syncInterval: defaultSyncInterval, compactAfter: defaultCompactAfter}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	dict := NewDict[K, V](hasher, o.mapOpts...)
+	if err := loadSnapshot(filepath.Join(dir, snapshotFile), dict); err != nil {
+		return nil, err
+	}
+	log, err := os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := log.Stat()
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	valid, err := replayLog(log, info.Size(), dict)
+	if err != nil {
+		log.Close()
+		return nil, fmt.Errorf("quickdict: replaying %s: %w", log.Name(), err)
+	}
+	if valid < info.Size() {
+		if err := log.Truncate(valid); err != nil {
+			log.Close()
+			return nil, err
+		}
+	}
+
+	d := &DurableQuickDict[K, V]{
+		dict: dict,
+		dir:  dir,
+		log:  log,
+		enc:  recordEncoder[K, V]{keys: dict.data.KeyCodec(), values: dict.data.ValueCodec()},
+		opts: o,
+		size: valid,
+	}
+	if o.sync == SyncPeriodically {
+		d.syncer = &janitor{stop: make(chan struct{}), done: make(chan struct{})}
+		go d.runSyncer(d.syncer, o.syncInterval)
+	}
+	return d, nil
+}
+
+func loadSnapshot[K comparable, V any](path string, dict *QuickDict[K, V]) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := dict.ReadFrom(f); err != nil {
+		return fmt.Errorf("quickdict: loading %s: %w", path, err)
+	}
+	return nil
+}
+
+// Get retrieves a value by key from the dictionary
+func (d *DurableQuickDict[K, V]) Get(key K) (V, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dict.Get(key)
+}
+
+// Size returns the number of entries in the dictionary
+func (d *DurableQuickDict[K, V]) Size() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dict.Size()
+}
+
+// All returns an iterator over the dictionary's key-value pairs. The
+// dictionary is locked until the iteration ends, so the loop body must not
+// use it.
+func (d *DurableQuickDict[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.dict.All()(yield)
+	}
+}
+
+// Set logs and then applies a key-value pair. The error is nil once the
+// record has been written, and synced under SyncAlways.
+func (d *DurableQuickDict[K, V]) Set(key K, value V) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.enc.begin(1)
+	if err := d.enc.set(key, value); err != nil {
+		return err
+	}
+	if err := d.write(); err != nil {
+		return err
+	}
+	d.dict.Set(key, value)
+	return d.commit()
+}
+
+// Delete logs and then applies the removal of a key
+func (d *DurableQuickDict[K, V]) Delete(key K) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.enc.begin(1)
+	if err := d.enc.delete(key); err != nil {
+		return err
+	}
+	if err := d.write(); err != nil {
+		return err
+	}
+	d.dict.Delete(key)
+	return d.commit()
+}
+
+// SetMany logs and then applies multiple key-value pairs as a single record,
+// so that after a crash either all or none of them are recovered
+func (d *DurableQuickDict[K, V]) SetMany(pairs map[K]V) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.enc.begin(len(pairs))
+	for key, value := range pairs {
+		if err := d.enc.set(key, value); err != nil {
+			return err
+		}
+	}
+	if err := d.write(); err != nil {
+		return err
+	}
+	d.dict.SetMany(pairs)
+	return d.commit()
+}
+
+// DeleteMany logs and then applies the removal of multiple keys as a single
+// record
+func (d *DurableQuickDict[K, V]) DeleteMany(keys []K) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.enc.begin(len(keys))
+	for _, key := range keys {
+		if err := d.enc.delete(key); err != nil {
+			return err
+		}
+	}
+	if err := d.write(); err != nil {
+		return err
+	}
+	d.dict.DeleteMany(keys)
+	return d.commit()
+}
+
+// Checkpoint writes a snapshot of the dictionary and empties the log
+func (d *DurableQuickDict[K, V]) Checkpoint() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.usable(); err != nil {
+		return err
+	}
+	return d.checkpoint()
+}
+
+// Sync flushes the log to stable storage
+func (d *DurableQuickDict[K, V]) Sync() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.usable(); err != nil {
+		return err
+	}
+	return d.sync()
+}
+
+// Close syncs and closes the log. The dictionary cannot be used afterwards.
+// Closing it again returns an error, as does closing it after a failed
+// background sync.
+func (d *DurableQuickDict[K, V]) Close() error {
+	// Take the syncer under the lock, so that only one Close stops it, but
+	// wait for it without the lock, which it needs to finish a sync
+	d.mu.Lock()
+	s := d.syncer
+	d.syncer = nil
+	d.mu.Unlock()
+	if s != nil {
+		close(s.stop)
+		<-s.done
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.log == nil {
+		return errDurableClosed
+	}
+	err := d.err
+	if err == nil {
+		err = d.sync()
+	}
+	if closeErr := d.log.Close(); err == nil {
+		err = closeErr
+	}
+	d.log = nil
+	return err
+}
+
+// usable returns the error that stops the dictionary from accepting writes,
+// if any
+func (d *DurableQuickDict[K, V]) usable() error {
+	if d.log == nil {
+		return errDurableClosed
+	}
+	return d.err
+}
+
+// write appends the encoded record to the log. A failed write is cut back
+// off the log, so that later records are not lost behind a partial one on
+// replay; if even that fails, the dictionary stops accepting writes.
+func (d *DurableQuickDict[K, V]) write() error {
+	if err := d.usable(); err != nil {
+		return err
+	}
+	record := d.enc.record()
+	if _, err := d.log.Write(record); err != nil {
+		if truncErr := d.log.Truncate(d.size); truncErr != nil {
+			d.err = fmt.Errorf("quickdict: log is damaged after a failed write: %w", truncErr)
+		}
+		return err
+	}
+	d.size += int64(len(record))
+	d.records++
+	d.dirty = true
+	return nil
+}
+
+// commit finishes a write once it has been applied, since the record would be
+// replayed on recovery even if syncing it fails
+func (d *DurableQuickDict[K, V]) commit() error {
+	if d.opts.sync == SyncAlways {
+		if err := d.sync(); err != nil {
+			return err
+		}
+	}
+	return d.maybeCompact()
+}
+
+func (d *DurableQuickDict[K, V]) sync() error {
+	if !d.dirty {
+		return nil
+	}
+	if err := d.log.Sync(); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
+
+func (d *DurableQuickDict[K, V]) maybeCompact() error {
+	if d.opts.compactAfter < 1 || d.records < d.opts.compactAfter || d.records <= d.dict.Size() {
+		return nil
+	}
+	return d.checkpoint()
+}
+
+// checkpoint replaces the snapshot and then empties the log. If it is
+// interrupted in between, the old log is replayed over the new snapshot on
+// recovery, which is harmless: replaying sets and deletes over a state that
+// already reflects them leaves it unchanged.
+func (d *DurableQuickDict[K, V]) checkpoint() error {
+	tmp, err := os.CreateTemp(d.dir, snapshotFile+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = d.dict.WriteTo(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(d.dir, snapshotFile)); err != nil {
+		return err
+	}
+	if err := syncDir(d.dir); err != nil {
+		return err
+	}
+
+	if err := d.log.Truncate(0); err != nil {
+		d.err = fmt.Errorf("quickdict: emptying log after checkpoint: %w", err)
+		return d.err
+	}
+	d.size, d.records, d.dirty = 0, 0, true
+	return d.sync()
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+func (d *DurableQuickDict[K, V]) runSyncer(s *janitor, interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			if d.log != nil && d.err == nil {
+				if err := d.sync(); err != nil {
+					d.err = fmt.Errorf("quickdict: background sync failed: %w", err)
+				}
+			}
+			d.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package quickdict
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDurableQuickDict(t *testing.T) {
+	// Test that every kind of write survives reopening
+	t.Run("Reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		d, err := OpenDurable[string, int](dir, nil)
+		if err != nil {
+			t.Fatalf("OpenDurable returned %v, expected nil", err)
+		}
+		d.Set("a", 1)
+		d.Set("b", 2)
+		d.SetMany(map[string]int{"c": 3, "d": 4, "e": 5})
+		d.Delete("a")
+		d.DeleteMany([]string{"c", "missing"})
+		d.Set("b", 20)
+		if err := d.Close(); err != nil {
+			t.Fatalf("Close() returned %v, expected nil", err)
+		}
+		if err := d.Set("f", 6); err == nil {
+			t.Errorf("Set after Close returned nil, expected an error")
+		}
+
+		d, err = OpenDurable[string, int](dir, nil)
+		if err != nil {
+			t.Fatalf("OpenDurable returned %v on reopening, expected nil", err)
+		}
+		defer d.Close()
+		expected := map[string]int{"b": 20, "d": 4, "e": 5}
+		if n := count(d.All()); n != len(expected) {
+			t.Errorf("All() yielded %d pairs after reopening, expected %d", n, len(expected))
+		}
+		if d.Size() != len(expected) {
+			t.Errorf("Size() = %d after reopening, expected %d", d.Size(), len(expected))
+		}
+		for key, value := range expected {
+			if got, exists := d.Get(key); !exists || got != value {
+				t.Errorf("Get(%q) = %d, %t; expected %d, true", key, got, exists, value)
+			}
+		}
+	})
+
+	// Test that a torn final record is discarded and the log stays usable
+	t.Run("Torn record", func(t *testing.T) {
+		dir := t.TempDir()
+		d, _ := OpenDurable[string, string](dir, nil)
+		d.Set("kept", "yes")
+		d.SetMany(map[string]string{"x": "1", "y": "2"})
+		d.Close()
+
+		path := filepath.Join(dir, logFile)
+		info, _ := os.Stat(path)
+		if err := os.Truncate(path, info.Size()-3); err != nil {
+			t.Fatalf("Truncate returned %v", err)
+		}
+		d, err := OpenDurable[string, string](dir, nil)
+		if err != nil {
+			t.Fatalf("OpenDurable returned %v with a torn record, expected nil", err)
+		}
+		if _, exists := d.Get("x"); exists || d.Size() != 1 {
+			t.Errorf("Size() = %d after a torn SetMany, expected the whole batch dropped", d.Size())
+		}
+		d.Set("after", "tear")
+		d.Close()
+
+		d, _ = OpenDurable[string, string](dir, nil)
+		defer d.Close()
+		if value, exists := d.Get("after"); !exists || value != "tear" || d.Size() != 2 {
+			t.Errorf("Get(\"after\") = %q, %t with Size() = %d; expected \"tear\", true, 2", value, exists, d.Size())
+		}
+	})
+
+	// Test that a damaged record in the middle of the log is an error that
+	// leaves the log alone, while one at the end is dropped as torn
+	t.Run("Damaged record", func(t *testing.T) {
+		dir := t.TempDir()
+		d, _ := OpenDurable[string, int](dir, nil)
+		for i := 0; i < 10; i++ {
+			d.Set(strconv.Itoa(i), i)
+		}
+		d.Close()
+		path := filepath.Join(dir, logFile)
+		log, _ := os.ReadFile(path)
+
+		damaged := slices.Clone(log)
+		damaged[walHeaderSize+1] ^= 0x40
+		os.WriteFile(path, damaged, 0o644)
+		if _, err := OpenDurable[string, int](dir, nil); !errors.Is(err, errBadRecord) {
+			t.Errorf("OpenDurable returned %v with the first record damaged, expected errBadRecord", err)
+		}
+		if after, _ := os.ReadFile(path); !bytes.Equal(after, damaged) {
+			t.Errorf("OpenDurable changed a log with a damaged record, expected it left alone")
+		}
+
+		damaged = slices.Clone(log)
+		damaged[len(damaged)-1] ^= 0x40
+		os.WriteFile(path, damaged, 0o644)
+		d, err := OpenDurable[string, int](dir, nil)
+		if err != nil {
+			t.Fatalf("OpenDurable returned %v with the last record damaged, expected nil", err)
+		}
+		defer d.Close()
+		if _, exists := d.Get("9"); exists || d.Size() != 9 {
+			t.Errorf("Size() = %d with the last record damaged, expected 9 without key 9", d.Size())
+		}
+	})
+
+	// Test automatic compaction into a snapshot
+	t.Run("Compaction", func(t *testing.T) {
+		dir := t.TempDir()
+		d, _ := OpenDurable[int, int](dir, nil, WithCompactAfter(100), WithSyncPolicy(SyncNever))
+		for i := 0; i < 1000; i++ {
+			d.Set(i, i)
+			if i%10 != 7 {
+				d.Delete(i)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, snapshotFile)); err != nil {
+			t.Errorf("no snapshot after 1000 writes: %v", err)
+		}
+		if info, _ := os.Stat(filepath.Join(dir, logFile)); info.Size() > 100*walHeaderSize*2 {
+			t.Errorf("log is %d bytes after compaction, expected at most 100 records", info.Size())
+		}
+		d.Close()
+
+		d, _ = OpenDurable[int, int](dir, nil)
+		defer d.Close()
+		if value, _ := d.Get(997); value != 997 || d.Size() != 100 {
+			t.Errorf("Get(997) = %d with Size() = %d after reopening, expected 997 and 100", value, d.Size())
+		}
+	})
+
+	// Test that an interrupted checkpoint, leaving both the new snapshot and
+	// the old log, recovers the same state
+	t.Run("Interrupted checkpoint", func(t *testing.T) {
+		dir := t.TempDir()
+		d, _ := OpenDurable[string, int](dir, nil, WithCompactAfter(0))
+		d.Set("a", 1)
+		d.Delete("a")
+		d.Set("b", 2)
+		log, _ := os.ReadFile(filepath.Join(dir, logFile))
+		if err := d.Checkpoint(); err != nil {
+			t.Fatalf("Checkpoint() returned %v, expected nil", err)
+		}
+		d.Close()
+		os.WriteFile(filepath.Join(dir, logFile), log, 0o644)
+
+		d, _ = OpenDurable[string, int](dir, nil)
+		defer d.Close()
+		if _, exists := d.Get("a"); exists || count(d.All()) != 1 {
+			t.Errorf("All() yielded %d pairs after replaying the old log over the snapshot, expected 1", count(d.All()))
+		}
+		if d.Size() != 1 {
+			t.Errorf("Size() = %d after replaying the old log over the snapshot, expected 1", d.Size())
+		}
+	})
+
+	// Test concurrent writers with background syncing
+	t.Run("Concurrent", func(t *testing.T) {
+		dir := t.TempDir()
+		d, _ := OpenDurable[string, int](dir, nil, WithSyncInterval(time.Millisecond), WithCompactAfter(50))
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					d.Set(strconv.Itoa(g*100+i), i)
+				}
+			}(g)
+		}
+		wg.Wait()
+		d.Close()
+
+		d, _ = OpenDurable[string, int](dir, nil)
+		defer d.Close()
+		if d.Size() != 400 {
+			t.Errorf("Size() = %d after reopening, expected 400", d.Size())
+		}
+	})
+
+	// Test that a failed background sync is returned by the next call
+	t.Run("Failed background sync", func(t *testing.T) {
+		d, err := OpenDurable[string, int](t.TempDir(), nil, WithSyncInterval(time.Millisecond))
+		if err != nil {
+			t.Fatalf("OpenDurable returned %v", err)
+		}
+		d.Set("a", 1)
+		d.mu.Lock()
+		d.log.Close()
+		d.dirty = true
+		d.mu.Unlock()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			d.mu.Lock()
+			failed := d.err != nil
+			d.mu.Unlock()
+			if failed {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("background sync of a closed log did not fail")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := d.Set("b", 2); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("Set after a failed sync returned %v, expected os.ErrClosed", err)
+		}
+		if err := d.Sync(); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("Sync after a failed sync returned %v, expected os.ErrClosed", err)
+		}
+		if err := d.Close(); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("Close after a failed sync returned %v, expected os.ErrClosed", err)
+		}
+	})
+
+	// Test concurrent Close calls racing writes; run with -race
+	t.Run("Concurrent Close", func(t *testing.T) {
+		d, err := OpenDurable[string, int](t.TempDir(), nil, WithSyncInterval(time.Millisecond))
+		if err != nil {
+			t.Fatalf("OpenDurable returned %v", err)
+		}
+		var wg sync.WaitGroup
+		errs := make(chan error, 4)
+		for g := 0; g < 4; g++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				errs <- d.Close()
+			}()
+			go func(g int) {
+				defer wg.Done()
+				d.Set(strconv.Itoa(g), g)
+			}(g)
+		}
+		wg.Wait()
+		close(errs)
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+			}
+		}
+		if succeeded != 1 {
+			t.Errorf("%d of 4 concurrent Close calls succeeded, expected 1", succeeded)
+		}
+		if err := d.Set("late", 0); err == nil {
+			t.Errorf("Set after Close returned nil, expected an error")
+		}
+	})
+}
+
+func count[K, V any](all iter.Seq2[K, V]) int {
+	n := 0
+	for range all {
+		n++
+	}
+	return n
+}
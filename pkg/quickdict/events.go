@@ -0,0 +1,62 @@
+package quickdict
+
+import "github.com/marpit19/goquickmap/pkg/quickmap"
+
+// Subscribe calls f with an Event after every change to the dictionary, and
+// returns a function that cancels the subscription. The events are those
+// described for quickmap.QuickMap.Subscribe; SetMany and DeleteMany report
+// each key separately, and entries that expire are reported as Deleted when
+// they are removed, possibly by the janitor's goroutine. f must not use the
+// dictionary. A zero QuickDict is first initialised as if by NewDict with no
+// hasher and no options.
+func (d *QuickDict[K, V]) Subscribe(f func(quickmap.Event[K, V])) (cancel func()) {
+	d.lock()
+	defer d.unlock()
+	if d.data == nil {
+		d.data = quickmap.NewMap[K, V](nil)
+	}
+	cancelData := d.data.Subscribe(f)
+	return func() {
+		d.lock()
+		defer d.unlock()
+		cancelData()
+	}
+}
+
+// SubscribeChan is Subscribe with events sent to a channel with room for
+// buffer events, as described for quickmap.QuickMap.SubscribeChan.
+// Cancelling closes the channel.
+func (d *QuickDict[K, V]) SubscribeChan(buffer int) (<-chan quickmap.Event[K, V], func()) {
+	events := make(chan quickmap.Event[K, V], buffer)
+	cancel := d.Subscribe(func(e quickmap.Event[K, V]) {
+		events <- e
+	})
+	return events, func() {
+		cancel()
+		close(events)
+	}
+}
+
+// Clear removes every entry from the dictionary, along with their TTLs
+func (d *QuickDict[K, V]) Clear() {
+	d.lock()
+	defer d.unlock()
+	d.ttl = nil
+	if d.data != nil {
+		d.data.Clear()
+	}
+}
+
+// Subscribe is QuickDict.Subscribe. A zero LinkedQuickDict is first
+// initialised as if by NewLinkedDict with no hasher and no options.
+func (d *LinkedQuickDict[K, V]) Subscribe(f func(quickmap.Event[K, V])) (cancel func()) {
+	d.initZero()
+	return d.QuickDict.Subscribe(f)
+}
+
+// SubscribeChan is QuickDict.SubscribeChan, initialising a zero
+// LinkedQuickDict as Subscribe does
+func (d *LinkedQuickDict[K, V]) SubscribeChan(buffer int) (<-chan quickmap.Event[K, V], func()) {
+	d.initZero()
+	return d.QuickDict.SubscribeChan(buffer)
+}
@@ -0,0 +1,113 @@
+package quickdict
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+type event = quickmap.Event[string, int]
+
+// takeEvents returns the events in events other than Resized, sorted by key,
+// and empties it
+func takeEvents(events *[]event) []event {
+	got := slices.DeleteFunc(*events, func(e event) bool { return e.Kind == quickmap.Resized })
+	*events = nil
+	slices.SortStableFunc(got, func(a, b event) int { return strings.Compare(a.Key, b.Key) })
+	return got
+}
+
+func TestQuickDictEvents(t *testing.T) {
+	// Test that SetMany and DeleteMany send one event per key
+	t.Run("Batches", func(t *testing.T) {
+		d := NewDict[string, int](nil)
+		var events []event
+		d.Subscribe(func(e event) { events = append(events, e) })
+
+		d.Set("a", 1)
+		d.SetMany(map[string]int{"a": 2, "b": 3})
+		expected := []event{
+			{Kind: quickmap.Inserted, Key: "a", Value: 1},
+			{Kind: quickmap.Updated, Key: "a", Value: 2, Old: 1},
+			{Kind: quickmap.Inserted, Key: "b", Value: 3},
+		}
+		if got := takeEvents(&events); !slices.Equal(got, expected) {
+			t.Errorf("Set and SetMany sent %v, expected %v", got, expected)
+		}
+
+		d.DeleteMany([]string{"a", "b", "c"})
+		expected = []event{
+			{Kind: quickmap.Deleted, Key: "a", Value: 2},
+			{Kind: quickmap.Deleted, Key: "b", Value: 3},
+		}
+		if got := takeEvents(&events); !slices.Equal(got, expected) {
+			t.Errorf("DeleteMany sent %v, expected %v", got, expected)
+		}
+	})
+
+	// Test that expired entries are reported as deleted, and that Clear drops TTLs
+	t.Run("Expiry and Clear", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		d := NewDict[string, int](nil)
+		d.SetClock(clock)
+		events, cancel := d.SubscribeChan(8)
+
+		d.SetWithTTL("a", 1, time.Second)
+		d.SetWithTTL("b", 2, time.Hour)
+		clock.Advance(time.Minute)
+		d.DeleteExpired()
+		d.Clear()
+		if _, ok := d.TTL("b"); ok {
+			t.Errorf("TTL(b) is set after Clear(), expected none")
+		}
+		cancel()
+
+		var got []event
+		for e := range events {
+			if e.Kind != quickmap.Resized {
+				got = append(got, e)
+			}
+		}
+		expected := []event{
+			{Kind: quickmap.Inserted, Key: "a", Value: 1},
+			{Kind: quickmap.Inserted, Key: "b", Value: 2},
+			{Kind: quickmap.Deleted, Key: "a", Value: 1},
+			{Kind: quickmap.Cleared},
+		}
+		if !slices.Equal(got, expected) {
+			t.Errorf("SetWithTTL, DeleteExpired and Clear sent %v, expected %v", got, expected)
+		}
+		if d.Size() != 0 {
+			t.Errorf("Size() = %d after Clear(), expected 0", d.Size())
+		}
+	})
+
+	// Test that subscribing initialises a zero QuickDict
+	t.Run("Zero dictionary", func(t *testing.T) {
+		var d QuickDict[string, int]
+		var events []event
+		d.Subscribe(func(e event) { events = append(events, e) })
+		d.Set("a", 1)
+		if len(events) != 1 {
+			t.Errorf("Set on a zero dictionary sent %v, expected one event", events)
+		}
+	})
+
+	// Test that subscribing to a zero LinkedQuickDict keeps it ordered
+	t.Run("Zero linked dictionary", func(t *testing.T) {
+		var d LinkedQuickDict[string, int]
+		events, cancel := d.SubscribeChan(2)
+		d.Set("b", 1)
+		d.Set("a", 2)
+		cancel()
+		if key, _, ok := d.First(); !ok || key != "b" {
+			t.Errorf("First() = %q, %t; expected \"b\", true", key, ok)
+		}
+		if n := len(events); n != 2 {
+			t.Errorf("SubscribeChan received %d events, expected 2", n)
+		}
+	})
+}
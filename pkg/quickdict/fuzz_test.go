@@ -0,0 +1,53 @@
+package quickdict
+
+import (
+	"testing"
+
+	"github.com/marpit19/goquickmap/internal/maptest"
+)
+
+// FuzzQuickDict checks QuickDict against the built-in map
+func FuzzQuickDict(f *testing.F) {
+	f.Add([]byte{0, 1, 10, 1, 1, 0, 1, 20, 1, 1, 2, 1, 1, 1})
+	f.Add([]byte{3, 3, 1, 1, 2, 2, 3, 3, 5, 4, 2, 1, 2, 5, 1, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := maptest.Check(data, dictAdapter{NewDictWithCapacity[int, int](nil, 1)}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// dictAdapter presents a QuickDict as a maptest.Map
+type dictAdapter struct {
+	d *QuickDict[int, int]
+}
+
+func (a dictAdapter) Insert(key, value int) {
+	a.d.Set(key, value)
+}
+
+func (a dictAdapter) Get(key int) (int, bool) {
+	return a.d.Get(key)
+}
+
+func (a dictAdapter) Delete(key int) {
+	a.d.Delete(key)
+}
+
+func (a dictAdapter) InsertMany(pairs map[int]int) {
+	a.d.SetMany(pairs)
+}
+
+func (a dictAdapter) DeleteMany(keys []int) {
+	a.d.DeleteMany(keys)
+}
+
+func (a dictAdapter) ForEach(f func(key, value int)) {
+	for key, value := range a.d.All() {
+		f(key, value)
+	}
+}
+
+func (a dictAdapter) Size() int {
+	return a.d.Size()
+}
@@ -0,0 +1,38 @@
+package quickdict
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/marpit19/goquickmap/internal/jsonenc"
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// MarshalJSON encodes the dictionary as a JSON object, as described for
+// quickmap.QuickMap.MarshalJSON. Expired entries are left out and TTLs are
+// not encoded.
+func (d *QuickDict[K, V]) MarshalJSON() ([]byte, error) {
+	if d.data == nil {
+		return []byte("{}"), nil
+	}
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	return d.data.MarshalJSON()
+}
+
+// UnmarshalJSON decodes a JSON object into the dictionary, adding to the
+// entries already present. Decoded keys have no TTL. A zero QuickDict is
+// first initialised as if by NewDict with no hasher and no options.
+func (d *QuickDict[K, V]) UnmarshalJSON(data []byte) error {
+	return d.DecodeJSON(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeJSON reads the next JSON value from dec into the dictionary, as
+// described for UnmarshalJSON, without building an intermediate Go map
+func (d *QuickDict[K, V]) DecodeJSON(dec *json.Decoder) error {
+	if d.data == nil {
+		d.data = quickmap.NewMap[K, V](nil)
+	}
+	return jsonenc.DecodeObject(dec, d.Set)
+}
@@ -0,0 +1,48 @@
+package quickdict
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+func TestJSON(t *testing.T) {
+	// Test a round trip that leaves out expired entries
+	t.Run("Round trip", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		d := NewDict[string, int](nil, quickmap.WithSortedJSON())
+		d.SetClock(clock)
+		d.Set("b", 2)
+		d.Set("a", 1)
+		d.SetWithTTL("gone", 3, time.Second)
+		clock.Advance(2 * time.Second)
+		data, err := json.Marshal(d)
+		if err != nil || string(data) != `{"a":1,"b":2}` {
+			t.Errorf("Marshal = %s, %v; expected {\"a\":1,\"b\":2}, nil", data, err)
+		}
+		var decoded QuickDict[string, int]
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal returned %v, expected nil", err)
+		}
+		if value, exists := decoded.Get("b"); !exists || value != 2 || decoded.Size() != 2 {
+			t.Errorf("Get(\"b\") = %d, %t with Size() = %d; expected 2, true, 2", value, exists, decoded.Size())
+		}
+	})
+
+	// Test that decoding over a key drops its TTL
+	t.Run("TTL dropped", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		d := NewDict[string, int](nil)
+		d.SetClock(clock)
+		d.SetWithTTL("a", 1, time.Second)
+		if err := json.Unmarshal([]byte(`{"a":5}`), d); err != nil {
+			t.Fatalf("Unmarshal returned %v, expected nil", err)
+		}
+		clock.Advance(time.Minute)
+		if value, exists := d.Get("a"); !exists || value != 5 {
+			t.Errorf("Get(\"a\") = %d, %t; expected 5, true", value, exists)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package quickdict
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// LinkedQuickDict is a QuickDict that iterates in insertion order, backed by
+// a quickmap.LinkedQuickMap. Setting an existing key keeps its position.
+// SetMany takes a map, so the keys it adds are appended in no particular
+// order among themselves. Entries with a TTL keep their position until they
+// expire.
+type LinkedQuickDict[K comparable, V any] struct {
+	QuickDict[K, V]
+	linked *quickmap.LinkedQuickMap[K, V]
+}
+
+// LinkedStringDict is a LinkedQuickDict with string keys and untyped values, as returned by NewLinked
+type LinkedStringDict = LinkedQuickDict[string, interface{}]
+
+// NewLinked creates and returns a new LinkedQuickDict with string keys
+func NewLinked(opts ...quickmap.Option) *LinkedStringDict {
+	return NewLinkedDict[string, interface{}](nil, opts...)
+}
+
+// NewLinkedDict creates and returns a new LinkedQuickDict that hashes its keys with hasher
+func NewLinkedDict[K comparable, V any](hasher quickmap.Hasher[K], opts ...quickmap.Option) *LinkedQuickDict[K, V] {
+	return newLinkedDict(quickmap.NewLinkedMap[K, V](hasher, opts...))
+}
+
+// NewLinkedDictWithCapacity creates and returns a new LinkedQuickDict with the specified hasher and initial capacity
+func NewLinkedDictWithCapacity[K comparable, V any](hasher quickmap.Hasher[K], initialCapacity int, opts ...quickmap.Option) *LinkedQuickDict[K, V] {
+	return newLinkedDict(quickmap.NewLinkedMapWithCapacity[K, V](hasher, initialCapacity, opts...))
+}
+
+func newLinkedDict[K comparable, V any](m *quickmap.LinkedQuickMap[K, V]) *LinkedQuickDict[K, V] {
+	return &LinkedQuickDict[K, V]{
+		QuickDict: QuickDict[K, V]{data: &m.QuickMap},
+		linked:    m,
+	}
+}
+
+// initZero initialises a zero LinkedQuickDict as if by NewLinkedDict with no
+// hasher and no options, so that decoding into it keeps the order
+func (d *LinkedQuickDict[K, V]) initZero() {
+	if d.linked == nil {
+		m := quickmap.NewLinkedMap[K, V](nil)
+		d.data, d.linked = &m.QuickMap, m
+	}
+}
+
+// UnmarshalJSON decodes a JSON object into the dictionary, as described for
+// QuickDict.UnmarshalJSON, appending new keys in the order they appear. A
+// zero LinkedQuickDict is first initialised as if by NewLinkedDict with no
+// hasher and no options.
+func (d *LinkedQuickDict[K, V]) UnmarshalJSON(data []byte) error {
+	return d.DecodeJSON(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeJSON reads the next JSON value from dec into the dictionary, as
+// described for UnmarshalJSON
+func (d *LinkedQuickDict[K, V]) DecodeJSON(dec *json.Decoder) error {
+	d.initZero()
+	return d.QuickDict.DecodeJSON(dec)
+}
+
+// ReadFrom replaces the contents of the dictionary with a snapshot, as
+// described for QuickDict.ReadFrom, in the order the snapshot holds them. A
+// zero LinkedQuickDict is first initialised as if by NewLinkedDict with no
+// hasher and no options.
+func (d *LinkedQuickDict[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	d.initZero()
+	return d.QuickDict.ReadFrom(r)
+}
+
+// MoveToFront makes key the first entry in iteration order, and reports whether the key was present
+func (d *LinkedQuickDict[K, V]) MoveToFront(key K) bool {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	return d.linked.MoveToFront(key)
+}
+
+// MoveToBack makes key the last entry in iteration order, and reports whether the key was present
+func (d *LinkedQuickDict[K, V]) MoveToBack(key K) bool {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	return d.linked.MoveToBack(key)
+}
+
+// First returns the first entry in iteration order, or false if the dictionary is empty
+func (d *LinkedQuickDict[K, V]) First() (K, V, bool) {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	return d.linked.First()
+}
+
+// Last returns the last entry in iteration order, or false if the dictionary is empty
+func (d *LinkedQuickDict[K, V]) Last() (K, V, bool) {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	return d.linked.Last()
+}
+
+// PopFirst removes and returns the first entry in iteration order, or false if the dictionary is empty
+func (d *LinkedQuickDict[K, V]) PopFirst() (K, V, bool) {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	key, value, ok := d.linked.PopFirst()
+	if ok {
+		d.forget(key)
+	}
+	return key, value, ok
+}
+
+// PopLast removes and returns the last entry in iteration order, or false if the dictionary is empty
+func (d *LinkedQuickDict[K, V]) PopLast() (K, V, bool) {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	key, value, ok := d.linked.PopLast()
+	if ok {
+		d.forget(key)
+	}
+	return key, value, ok
+}
@@ -0,0 +1,65 @@
+package quickdict
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestLinkedQuickDict(t *testing.T) {
+	d := NewLinkedDict[string, int](nil)
+	for i, key := range []string{"x", "y", "z", "w"} {
+		d.Set(key, i)
+	}
+	d.Set("x", 10)
+	d.MoveToBack("y")
+	if keys := slices.Collect(d.Keys()); !slices.Equal(keys, []string{"x", "z", "w", "y"}) {
+		t.Errorf("Keys() = %v, expected [x z w y]", keys)
+	}
+	if values := slices.Collect(d.Values()); !slices.Equal(values, []int{10, 2, 3, 1}) {
+		t.Errorf("Values() = %v, expected [10 2 3 1]", values)
+	}
+	d.MoveToFront("w")
+	if key, value, ok := d.PopFirst(); !ok || key != "w" || value != 3 {
+		t.Errorf("PopFirst() = %q, %d, %t; expected \"w\", 3, true", key, value, ok)
+	}
+	if key, _, ok := d.Last(); !ok || key != "y" {
+		t.Errorf("Last() = %q, %t; expected \"y\", true", key, ok)
+	}
+	if key, _, ok := d.PopLast(); !ok || key != "y" || d.Size() != 2 {
+		t.Errorf("PopLast() = %q, %t with Size() = %d; expected \"y\", true, 2", key, ok, d.Size())
+	}
+	if key, _, ok := d.First(); !ok || key != "x" {
+		t.Errorf("First() = %q, %t; expected \"x\", true", key, ok)
+	}
+
+	s := NewLinked()
+	s.Set("b", 1)
+	s.Set("a", 2)
+	if keys := slices.Collect(s.Keys()); !slices.Equal(keys, []string{"b", "a"}) {
+		t.Errorf("Keys() = %v, expected [b a]", keys)
+	}
+}
+
+func TestLinkedQuickDictZeroValue(t *testing.T) {
+	var d LinkedQuickDict[string, int]
+	if err := d.UnmarshalJSON([]byte(`{"b": 1, "a": 2}`)); err != nil {
+		t.Fatalf("UnmarshalJSON returned %v", err)
+	}
+	if key, _, ok := d.First(); !ok || key != "b" {
+		t.Errorf("First() = %q, %t after UnmarshalJSON; expected \"b\", true", key, ok)
+	}
+
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo returned %v", err)
+	}
+	var read LinkedQuickDict[string, int]
+	if _, err := read.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom returned %v", err)
+	}
+	read.MoveToFront("a")
+	if keys := slices.Collect(read.Keys()); !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("Keys() = %v after ReadFrom and MoveToFront, expected [a b]", keys)
+	}
+}
@@ -1,72 +1,234 @@
 package quickdict
 
 import (
+	"iter"
+	"sync"
+
 	"github.com/marpit19/goquickmap/pkg/quickmap"
 )
 
 // QuickDict represnts a dictionary data structure
-type QuickDict struct {
-	data *quickmap.QuickMap
+type QuickDict[K comparable, V any] struct {
+	data *quickmap.QuickMap[K, V]
+
+	// TTL state; see ttl.go. ttl is nil until SetWithTTL is first called, and
+	// mu is only used while a janitor is running.
+	ttl       *expiry[K]
+	clock     Clock
+	janitor   *janitor
+	mu        sync.Mutex
+	iterating int
 }
 
+// StringDict is a QuickDict with string keys and untyped values, as returned by New
+type StringDict = QuickDict[string, interface{}]
+
 // New creates and returns a new QuickDict
-func New() *QuickDict {
-	return &QuickDict{
-		data: quickmap.New(),
+func New(opts ...quickmap.Option) *StringDict {
+	return &QuickDict[string, interface{}]{
+		data: quickmap.New(opts...),
 	}
 }
 
 // NewWithCapacity creates and returns a new QuickDict with the specified initial capacity
-func NewWithCapacity(initialCapacity int) *QuickDict {
-	return &QuickDict{
-		data: quickmap.NewWithCapacity(initialCapacity),
+func NewWithCapacity(initialCapacity int, opts ...quickmap.Option) *StringDict {
+	return &QuickDict[string, interface{}]{
+		data: quickmap.NewWithCapacity(initialCapacity, opts...),
+	}
+}
+
+// NewDict creates and returns a new QuickDict that hashes its keys with hasher.
+// A nil hasher behaves as described for quickmap.NewMap.
+func NewDict[K comparable, V any](hasher quickmap.Hasher[K], opts ...quickmap.Option) *QuickDict[K, V] {
+	return &QuickDict[K, V]{
+		data: quickmap.NewMap[K, V](hasher, opts...),
 	}
 }
 
-// Set inserts or updates a key-value pair in the dictionary
-func (d *QuickDict) Set(key string, value interface{}) {
+// NewDictWithCapacity creates and returns a new QuickDict with the specified hasher and initial capacity
+func NewDictWithCapacity[K comparable, V any](hasher quickmap.Hasher[K], initialCapacity int, opts ...quickmap.Option) *QuickDict[K, V] {
+	return &QuickDict[K, V]{
+		data: quickmap.NewMapWithCapacity[K, V](hasher, initialCapacity, opts...),
+	}
+}
+
+// Set inserts or updates a key-value pair in the dictionary. Any TTL the key
+// had is dropped.
+func (d *QuickDict[K, V]) Set(key K, value V) {
+	d.lock()
+	defer d.unlock()
+	d.forget(key)
 	d.data.Insert(key, value)
 }
 
 // Get retrieves a value by key from the dictionary
-func (d *QuickDict) Get(key string) (interface{}, bool) {
+func (d *QuickDict[K, V]) Get(key K) (V, bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
 	return d.data.Get(key)
 }
 
 // Delete removes a key-value pair from the dictionary
-func (d *QuickDict) Delete(key string) {
+func (d *QuickDict[K, V]) Delete(key K) {
+	d.lock()
+	defer d.unlock()
+	d.forget(key)
 	d.data.Delete(key)
 }
 
-// Size returns the number of key-value pairs in the dictionary
-func (d *QuickDict) Size() int {
+// Compute looks key up once and calls f with its current value and whether it
+// exists. If f returns true the key is set to the value f returns, keeping
+// any TTL, otherwise it is deleted. Compute returns the key's value afterwards
+// and whether it is present. f must not use the dictionary.
+func (d *QuickDict[K, V]) Compute(key K, f func(old V, exists bool) (V, bool)) (V, bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	value, ok := d.data.Compute(key, f)
+	if !ok {
+		d.forget(key)
+	}
+	return value, ok
+}
+
+// GetOrInsert returns the existing value for key if present. Otherwise it sets
+// key to value and returns it. loaded reports whether the key was present.
+func (d *QuickDict[K, V]) GetOrInsert(key K, value V) (actual V, loaded bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	return d.data.GetOrInsert(key, value)
+}
+
+// LoadAndDelete deletes key and returns its previous value, if any. loaded
+// reports whether the key was present.
+func (d *QuickDict[K, V]) LoadAndDelete(key K) (previous V, loaded bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	d.forget(key)
+	return d.data.LoadAndDelete(key)
+}
+
+// Swap sets key to value, dropping any TTL, and returns the previous value,
+// if any. loaded reports whether the key was present.
+func (d *QuickDict[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	d.forget(key)
+	return d.data.Swap(key, value)
+}
+
+// CompareAndSwap sets key to new if the key is present and its value equals
+// old, and reports whether it did. Any TTL is kept. It panics if V is not
+// comparable.
+func (d *QuickDict[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	return d.data.CompareAndSwap(key, old, new)
+}
+
+// Update replaces the value of key with the result of f applied to it, keeping
+// any TTL, and reports whether the key was present
+func (d *QuickDict[K, V]) Update(key K, f func(old V) V) (updated bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	return d.data.Update(key, f)
+}
+
+// Size returns the number of key-value pairs in the dictionary, after
+// deleting any that have expired
+func (d *QuickDict[K, V]) Size() int {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
 	return d.data.Size()
 }
 
-// Keys returns a slice of all keys in the dictionary
-func (d *QuickDict) Keys() []string {
-	keys := make([]string, 0, d.Size())
-	d.data.ForEach(func(key string, value interface{}) {
-		keys = append(keys, key)
-	})
-	return keys
+// All returns an iterator over the key-value pairs in the dictionary. Entries
+// that have expired are deleted before the iteration starts. The dictionary
+// may be modified during iteration; entries deleted before they are reached
+// are not visited, as described for quickmap.QuickMap.All.
+func (d *QuickDict[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		d.beginIteration()
+		defer d.endIteration()
+		d.data.All()(yield)
+	}
 }
 
-// Values returns a slice of all values in the dictionary
-func (d *QuickDict) Values() []interface{} {
-	values := make([]interface{}, 0, d.Size())
-	d.data.ForEach(func(key string, value interface{}) {
-		values = append(values, value)
-	})
-	return values
+// Keys returns an iterator over the keys in the dictionary. Use
+// slices.Collect to gather them into a slice.
+func (d *QuickDict[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		d.beginIteration()
+		defer d.endIteration()
+		d.data.Keys()(yield)
+	}
+}
+
+// Values returns an iterator over the values in the dictionary
+func (d *QuickDict[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		d.beginIteration()
+		defer d.endIteration()
+		d.data.Values()(yield)
+	}
 }
 
-// SetMany inserts or updates multiple key-value pairs in the dictionary
-func (d *QuickDict) SetMany(pairs map[string]interface{}) {
+// SetMany inserts or updates multiple key-value pairs in the dictionary,
+// dropping any TTLs they had
+func (d *QuickDict[K, V]) SetMany(pairs map[K]V) {
+	d.lock()
+	defer d.unlock()
+	for key := range pairs {
+		d.forget(key)
+	}
 	d.data.InsertMany(pairs)
 }
 
+// DeleteFunc removes every key-value pair for which del returns true, in a
+// single pass over the dictionary
+func (d *QuickDict[K, V]) DeleteFunc(del func(key K, value V) bool) {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	d.data.DeleteFunc(func(key K, value V) bool {
+		if del(key, value) {
+			d.forget(key)
+			return true
+		}
+		return false
+	})
+}
+
 // DeleteMany removes multiple key-value pairs from the dictionary
-func (d *QuickDict) DeleteMany(keys []string) {
+func (d *QuickDict[K, V]) DeleteMany(keys []K) {
+	d.lock()
+	defer d.unlock()
+	for _, key := range keys {
+		d.forget(key)
+	}
 	d.data.DeleteMany(keys)
 }
+
+// Compact rebuilds the dictionary at the smallest capacity that holds its current entries
+func (d *QuickDict[K, V]) Compact() {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	d.data.Compact()
+}
+
+// Stats returns the statistics of the dictionary's table, as described for
+// quickmap.QuickMap.Stats, after deleting any entries that have expired
+func (d *QuickDict[K, V]) Stats() quickmap.Stats {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	return d.data.Stats()
+}
@@ -2,8 +2,12 @@ package quickdict
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"testing"
+	"time"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
 )
 
 func TestQuickDict(t *testing.T) {
@@ -37,11 +41,11 @@ func TestQuickDict(t *testing.T) {
 		if d.Size() != 2 {
 			t.Errorf("Size() = %d, expected 2", d.Size())
 		}
-		keys := d.Keys()
+		keys := slices.Collect(d.Keys())
 		if len(keys) != 2 {
 			t.Errorf("len(Keys()) = %d, expected 2", len(keys))
 		}
-		values := d.Values()
+		values := slices.Collect(d.Values())
 		if len(values) != 2 {
 			t.Errorf("len(Values()) = %d, expected 2", len(values))
 		}
@@ -110,6 +114,132 @@ func TestQuickDict(t *testing.T) {
 	})
 }
 
+func TestQuickDictGeneric(t *testing.T) {
+	// Test typed values with integer keys
+	t.Run("Typed values", func(t *testing.T) {
+		d := NewDict[int64, float64](nil)
+		d.SetMany(map[int64]float64{1: 1.5, 2: 2.5})
+		d.Set(3, 3.5)
+		value, exists := d.Get(2)
+		if !exists || value != 2.5 {
+			t.Errorf("Get(2) = %v, %t; expected 2.5, true", value, exists)
+		}
+		d.DeleteMany([]int64{1, 3})
+		if d.Size() != 1 {
+			t.Errorf("After DeleteMany, Size() = %d, expected 1", d.Size())
+		}
+		if keys := slices.Collect(d.Keys()); len(keys) != 1 || keys[0] != 2 {
+			t.Errorf("Keys() = %v, expected [2]", keys)
+		}
+	})
+
+	// Test Compact
+	t.Run("Compact", func(t *testing.T) {
+		d := NewDict[int, int](nil, quickmap.WithShrinkFactor(0.2))
+		for i := 0; i < 1000; i++ {
+			d.Set(i, i*i)
+		}
+		d.DeleteMany(slices.Collect(d.Keys())[:500])
+		d.Compact()
+		if d.Size() != 500 || len(slices.Collect(d.Values())) != 500 {
+			t.Errorf("After Compact, Size() = %d, expected 500", d.Size())
+		}
+	})
+
+	// Test that Stats leaves out expired entries
+	t.Run("Stats", func(t *testing.T) {
+		d := NewDictWithCapacity[string, int](nil, 64)
+		clock := &manualClock{now: time.Unix(0, 0)}
+		d.SetClock(clock)
+		d.Set("a", 1)
+		d.SetWithTTL("b", 2, time.Second)
+		clock.Advance(time.Second)
+		if stats := d.Stats(); stats.Size != 1 || stats.Buckets != 64 {
+			t.Errorf("Stats() = %d entries in %d buckets, expected 1 in 64", stats.Size, stats.Buckets)
+		}
+	})
+
+	// Test iterating with early exit
+	t.Run("All", func(t *testing.T) {
+		d := NewDict[int, string](nil)
+		for i := 0; i < 100; i++ {
+			d.Set(i, strconv.Itoa(i))
+		}
+		visited := 0
+		for key, value := range d.All() {
+			if value != strconv.Itoa(key) {
+				t.Errorf("All() yielded %d = %q, expected %q", key, value, strconv.Itoa(key))
+			}
+			if visited++; visited == 5 {
+				break
+			}
+		}
+		if visited != 5 {
+			t.Errorf("All() visited %d pairs before break, expected 5", visited)
+		}
+	})
+
+	// Test DeleteFunc, including deleting while iterating
+	t.Run("DeleteFunc", func(t *testing.T) {
+		d := NewDict[int, int](nil)
+		for i := 0; i < 100; i++ {
+			d.Set(i, i%10)
+		}
+		d.DeleteFunc(func(key, value int) bool { return value == 0 })
+		for key := range d.Keys() {
+			if key%10 == 1 {
+				d.Delete(key)
+			}
+		}
+		if d.Size() != 80 {
+			t.Errorf("Size() = %d after DeleteFunc and deleting while iterating, expected 80", d.Size())
+		}
+	})
+
+	// Test the compound operations
+	t.Run("Compound operations", func(t *testing.T) {
+		d := NewDict[string, int](nil)
+		if actual, loaded := d.GetOrInsert("a", 1); loaded || actual != 1 {
+			t.Errorf("GetOrInsert(\"a\") = %d, %t; expected 1, false", actual, loaded)
+		}
+		if previous, loaded := d.Swap("a", 2); !loaded || previous != 1 {
+			t.Errorf("Swap(\"a\") = %d, %t; expected 1, true", previous, loaded)
+		}
+		if !d.CompareAndSwap("a", 2, 3) || !d.Update("a", func(old int) int { return old * 10 }) {
+			t.Errorf("CompareAndSwap or Update on \"a\" failed, expected both to succeed")
+		}
+		d.Compute("b", func(old int, exists bool) (int, bool) { return 4, !exists })
+		if previous, loaded := d.LoadAndDelete("a"); !loaded || previous != 30 {
+			t.Errorf("LoadAndDelete(\"a\") = %d, %t; expected 30, true", previous, loaded)
+		}
+		if value, exists := d.Get("b"); !exists || value != 4 || d.Size() != 1 {
+			t.Errorf("Get(\"b\") = %d, %t with Size() = %d; expected 4, true, 1", value, exists, d.Size())
+		}
+	})
+
+	// Test every backend behind the same API
+	for _, backend := range []quickmap.Backend{quickmap.Chaining, quickmap.Swiss, quickmap.RobinHood} {
+		t.Run(backend.String(), func(t *testing.T) {
+			d := NewDictWithCapacity[string, int](nil, 1, quickmap.WithBackend(backend))
+			for i := 0; i < 100; i++ {
+				d.Set(strconv.Itoa(i), i)
+			}
+			d.DeleteMany([]string{"0", "50", "99"})
+			d.Delete("1")
+			if d.Size() != 96 {
+				t.Errorf("Size() = %d, expected 96", d.Size())
+			}
+			keys, values := slices.Collect(d.Keys()), slices.Collect(d.Values())
+			if len(keys) != 96 || len(values) != 96 {
+				t.Errorf("len(Keys()) = %d, len(Values()) = %d; expected 96", len(keys), len(values))
+			}
+			if value, exists := d.Get("42"); !exists || value != 42 {
+				t.Errorf("Get(\"42\") = %d, %t; expected 42, true", value, exists)
+			}
+		})
+	}
+}
+
 func BenchmarkQuickDict(b *testing.B) {
 	d := New()
 
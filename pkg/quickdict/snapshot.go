@@ -0,0 +1,38 @@
+package quickdict
+
+import (
+	"io"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// WriteTo writes a snapshot of the dictionary to w, as described for
+// quickmap.QuickMap.WriteTo. Expired entries are left out and TTLs are not
+// written.
+func (d *QuickDict[K, V]) WriteTo(w io.Writer) (int64, error) {
+	if d.data == nil {
+		var empty quickmap.QuickMap[K, V]
+		return empty.WriteTo(w)
+	}
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+	return d.data.WriteTo(w)
+}
+
+// ReadFrom replaces the contents of the dictionary with a snapshot read from
+// r, as described for quickmap.QuickMap.ReadFrom. Keys loaded from the
+// snapshot have no TTL. A zero QuickDict is first initialised as if by
+// NewDict with no hasher and no options.
+func (d *QuickDict[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	if d.data == nil {
+		d.data = quickmap.NewMap[K, V](nil)
+	}
+	d.lock()
+	defer d.unlock()
+	n, err := d.data.ReadFrom(r)
+	if err == nil {
+		d.ttl = nil
+	}
+	return n, err
+}
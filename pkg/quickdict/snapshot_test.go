@@ -0,0 +1,39 @@
+package quickdict
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	// Test that expired entries and TTLs are left out of a snapshot
+	clock := &manualClock{now: time.Unix(0, 0)}
+	d := NewDict[string, float64](nil)
+	d.SetClock(clock)
+	d.Set("pi", 3.14)
+	d.SetWithTTL("e", 2.71, time.Minute)
+	d.SetWithTTL("gone", 0, time.Second)
+	clock.Advance(2 * time.Second)
+
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo returned %v, expected nil", err)
+	}
+	loaded := NewDict[string, float64](nil)
+	loaded.SetClock(clock)
+	loaded.SetWithTTL("old", 1, time.Second)
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom returned %v, expected nil", err)
+	}
+	if _, exists := loaded.Get("gone"); exists {
+		t.Errorf("Get(\"gone\") returned true, expected the expired entry left out")
+	}
+	if _, ok := loaded.TTL("e"); ok {
+		t.Errorf("TTL(\"e\") returned true, expected no TTL after ReadFrom")
+	}
+	clock.Advance(time.Hour)
+	if value, exists := loaded.Get("e"); !exists || value != 2.71 || loaded.Size() != 2 {
+		t.Errorf("Get(\"e\") = %v, %t with Size() = %d; expected 2.71, true, 2", value, exists, loaded.Size())
+	}
+}
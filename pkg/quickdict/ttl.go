@@ -0,0 +1,229 @@
+package quickdict
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Clock tells a QuickDict the current time when it checks TTLs. Tests can
+// substitute a clock they advance by hand; see SetClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// SetWithTTL inserts or updates a key-value pair that expires once ttl has
+// passed. An expired entry is never returned: Get and the compound operations
+// treat it as absent, and Size, All, Keys and Values delete every expired
+// entry before they count or iterate. A janitor started with StartJanitor
+// also deletes expired entries in the background. A ttl that is not positive
+// expires the entry immediately.
+func (d *QuickDict[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	d.lock()
+	defer d.unlock()
+	if d.ttl == nil {
+		d.ttl = newExpiry[K]()
+	}
+	d.ttl.set(key, d.now().Add(ttl))
+	d.data.Insert(key, value)
+}
+
+// TTL returns the time left before key expires. ok is false if the key is
+// absent or has no TTL.
+func (d *QuickDict[K, V]) TTL(key K) (ttl time.Duration, ok bool) {
+	d.lock()
+	defer d.unlock()
+	d.expire(key)
+	if d.ttl == nil {
+		return 0, false
+	}
+	deadline, ok := d.ttl.deadlines[key]
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(d.now()), true
+}
+
+// DeleteExpired deletes every entry whose TTL has passed. It is what the
+// janitor runs, and can be called directly instead.
+func (d *QuickDict[K, V]) DeleteExpired() {
+	d.lock()
+	defer d.unlock()
+	d.deleteExpired()
+}
+
+// SetClock replaces the clock used to check TTLs, which defaults to the
+// system clock. Deadlines already set are kept as absolute times.
+func (d *QuickDict[K, V]) SetClock(clock Clock) {
+	d.lock()
+	defer d.unlock()
+	d.clock = clock
+}
+
+// StartJanitor starts a goroutine that calls DeleteExpired every interval,
+// until Close is called. While it runs, the dictionary's methods synchronise
+// with it through a mutex, though the dictionary is still meant to be used
+// from one goroutine at a time. The janitor skips its sweep while an
+// iteration is in progress. Starting a janitor stops any previous one.
+func (d *QuickDict[K, V]) StartJanitor(interval time.Duration) {
+	d.Close()
+	j := &janitor{stop: make(chan struct{}), done: make(chan struct{})}
+	d.janitor = j
+	go d.runJanitor(j, interval)
+}
+
+func (d *QuickDict[K, V]) runJanitor(j *janitor, interval time.Duration) {
+	defer close(j.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			if d.iterating == 0 {
+				d.deleteExpired()
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// Close stops the janitor, if one is running, and waits for it to exit. The
+// dictionary remains usable, with TTLs checked lazily. Close always returns
+// nil.
+func (d *QuickDict[K, V]) Close() error {
+	if j := d.janitor; j != nil {
+		close(j.stop)
+		<-j.done
+		d.janitor = nil
+	}
+	return nil
+}
+
+type janitor struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// lock and unlock guard the dictionary's state against the janitor, and
+// only do anything while one is running
+func (d *QuickDict[K, V]) lock() {
+	if d.janitor != nil {
+		d.mu.Lock()
+	}
+}
+
+func (d *QuickDict[K, V]) unlock() {
+	if d.janitor != nil {
+		d.mu.Unlock()
+	}
+}
+
+// beginIteration deletes expired entries and registers an iteration, so that
+// the janitor leaves the map alone until endIteration
+func (d *QuickDict[K, V]) beginIteration() {
+	d.lock()
+	d.deleteExpired()
+	d.iterating++
+	d.unlock()
+}
+
+func (d *QuickDict[K, V]) endIteration() {
+	d.lock()
+	d.iterating--
+	d.unlock()
+}
+
+func (d *QuickDict[K, V]) now() time.Time {
+	if d.clock == nil {
+		return time.Now()
+	}
+	return d.clock.Now()
+}
+
+// expire deletes key if its TTL has passed
+func (d *QuickDict[K, V]) expire(key K) {
+	if d.ttl == nil {
+		return
+	}
+	if deadline, ok := d.ttl.deadlines[key]; ok && !d.now().Before(deadline) {
+		delete(d.ttl.deadlines, key)
+		d.data.Delete(key)
+	}
+}
+
+// forget drops the TTL of key, which is being replaced or deleted
+func (d *QuickDict[K, V]) forget(key K) {
+	if d.ttl != nil {
+		delete(d.ttl.deadlines, key)
+	}
+}
+
+func (d *QuickDict[K, V]) deleteExpired() {
+	if d.ttl == nil {
+		return
+	}
+	d.ttl.popExpired(d.now(), func(key K) {
+		d.data.Delete(key)
+	})
+}
+
+// expiry tracks the deadlines of the keys that have a TTL. The queue orders
+// them for sweeping; it can hold stale entries for keys whose TTL has since
+// changed or been dropped, which are skipped when they reach the front.
+type expiry[K comparable] struct {
+	deadlines map[K]time.Time
+	queue     deadlineQueue[K]
+}
+
+func newExpiry[K comparable]() *expiry[K] {
+	return &expiry[K]{deadlines: make(map[K]time.Time)}
+}
+
+func (e *expiry[K]) set(key K, deadline time.Time) {
+	e.deadlines[key] = deadline
+	heap.Push(&e.queue, deadlineEntry[K]{key, deadline})
+	// Rebuild the queue once stale entries dominate it
+	if len(e.queue) > 2*len(e.deadlines)+16 {
+		e.queue = e.queue[:0]
+		for key, deadline := range e.deadlines {
+			e.queue = append(e.queue, deadlineEntry[K]{key, deadline})
+		}
+		heap.Init(&e.queue)
+	}
+}
+
+// popExpired calls f with every key whose deadline is not after now, and forgets it
+func (e *expiry[K]) popExpired(now time.Time, f func(key K)) {
+	for len(e.queue) > 0 && !now.Before(e.queue[0].deadline) {
+		entry := heap.Pop(&e.queue).(deadlineEntry[K])
+		if deadline, ok := e.deadlines[entry.key]; ok && deadline.Equal(entry.deadline) {
+			delete(e.deadlines, entry.key)
+			f(entry.key)
+		}
+	}
+}
+
+type deadlineEntry[K comparable] struct {
+	key      K
+	deadline time.Time
+}
+
+// deadlineQueue is a min-heap of deadlines for container/heap
+type deadlineQueue[K comparable] []deadlineEntry[K]
+
+func (q deadlineQueue[K]) Len() int           { return len(q) }
+func (q deadlineQueue[K]) Less(i, j int) bool { return q[i].deadline.Before(q[j].deadline) }
+func (q deadlineQueue[K]) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *deadlineQueue[K]) Push(x any) {
+	*q = append(*q, x.(deadlineEntry[K]))
+}
+
+func (q *deadlineQueue[K]) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,163 @@
+package quickdict
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock is a Clock that only moves when advanced
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestQuickDictTTL(t *testing.T) {
+	newDict := func() (*QuickDict[string, int], *manualClock) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		d := NewDict[string, int](nil)
+		d.SetClock(clock)
+		return d, clock
+	}
+
+	// Test lazy expiry in Get, Size, Keys and Values
+	t.Run("Lazy expiry", func(t *testing.T) {
+		d, clock := newDict()
+		d.SetWithTTL("short", 1, time.Second)
+		d.SetWithTTL("long", 2, time.Minute)
+		d.Set("forever", 3)
+		if value, exists := d.Get("short"); !exists || value != 1 {
+			t.Errorf("Get(\"short\") = %d, %t before its TTL; expected 1, true", value, exists)
+		}
+		if ttl, ok := d.TTL("long"); !ok || ttl != time.Minute {
+			t.Errorf("TTL(\"long\") = %v, %t; expected 1m0s, true", ttl, ok)
+		}
+		if _, ok := d.TTL("forever"); ok {
+			t.Errorf("TTL(\"forever\") returned true for a key without a TTL")
+		}
+
+		clock.Advance(time.Second)
+		if _, exists := d.Get("short"); exists {
+			t.Errorf("Get(\"short\") returned true at its deadline, expected false")
+		}
+		if d.Size() != 2 {
+			t.Errorf("Size() = %d after one entry expired, expected 2", d.Size())
+		}
+		clock.Advance(time.Minute)
+		if keys := slices.Collect(d.Keys()); !slices.Equal(keys, []string{"forever"}) {
+			t.Errorf("Keys() = %v, expected [forever]", keys)
+		}
+		if values := slices.Collect(d.Values()); !slices.Equal(values, []int{3}) {
+			t.Errorf("Values() = %v, expected [3]", values)
+		}
+	})
+
+	// Test how writes change an existing TTL
+	t.Run("Replacing entries", func(t *testing.T) {
+		d, clock := newDict()
+		d.SetWithTTL("set", 1, time.Second)
+		d.Set("set", 2)
+		d.SetWithTTL("update", 1, time.Second)
+		d.Update("update", func(old int) int { return old + 1 })
+		d.SetWithTTL("renew", 1, time.Second)
+		clock.Advance(time.Second / 2)
+		d.SetWithTTL("renew", 2, time.Second)
+		d.SetWithTTL("deleted", 1, time.Second)
+		d.Delete("deleted")
+		d.Set("deleted", 2)
+
+		clock.Advance(time.Second / 2)
+		for key, expected := range map[string]bool{"set": true, "update": false, "renew": true, "deleted": true} {
+			if _, exists := d.Get(key); exists != expected {
+				t.Errorf("Get(%q) = %t after 1s, expected %t", key, exists, expected)
+			}
+		}
+		if _, exists := d.GetOrInsert("update", 5); exists {
+			t.Errorf("GetOrInsert(\"update\") found the expired entry")
+		}
+		clock.Advance(time.Second)
+		if _, exists := d.Get("renew"); exists {
+			t.Errorf("Get(\"renew\") returned true after its renewed TTL, expected false")
+		}
+		if keys := slices.Collect(d.Keys()); len(keys) != 3 {
+			t.Errorf("Keys() = %v, expected 3 keys", keys)
+		}
+	})
+
+	// Test DeleteExpired and the janitor
+	t.Run("Janitor", func(t *testing.T) {
+		d, clock := newDict()
+		for i := 0; i < 100; i++ {
+			d.SetWithTTL(strconv.Itoa(i), i, time.Duration(i+1)*time.Second)
+		}
+		clock.Advance(10 * time.Second)
+		d.DeleteExpired()
+		if d.data.Size() != 90 {
+			t.Errorf("DeleteExpired left %d entries, expected 90", d.data.Size())
+		}
+
+		d.StartJanitor(time.Millisecond)
+		defer d.Close()
+		clock.Advance(40 * time.Second)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			d.lock()
+			remaining := d.data.Size()
+			d.unlock()
+			if remaining == 50 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Janitor left %d entries, expected 50", remaining)
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		// The dictionary stays usable alongside the janitor; run with -race
+		for i := 0; i < 1000; i++ {
+			key := strconv.Itoa(i % 200)
+			d.SetWithTTL(key, i, time.Duration(i%7)*time.Second)
+			d.Get(key)
+			if i%100 == 0 {
+				clock.Advance(time.Second)
+				for range d.All() {
+					d.Delete(key)
+				}
+			}
+		}
+		if err := d.Close(); err != nil || d.Close() != nil {
+			t.Errorf("Close() = %v, expected nil", err)
+		}
+	})
+
+	// Test that LinkedQuickDict skips expired entries
+	t.Run("Linked", func(t *testing.T) {
+		clock := &manualClock{now: time.Unix(0, 0)}
+		d := NewLinkedDict[string, int](nil)
+		d.SetClock(clock)
+		d.SetWithTTL("a", 1, time.Second)
+		d.Set("b", 2)
+		d.SetWithTTL("c", 3, time.Second)
+		clock.Advance(time.Second)
+		if key, _, ok := d.First(); !ok || key != "b" {
+			t.Errorf("First() = %q, %t; expected \"b\", true", key, ok)
+		}
+		if key, _, ok := d.PopLast(); !ok || key != "b" || d.Size() != 0 {
+			t.Errorf("PopLast() = %q, %t with Size() = %d; expected \"b\", true, 0", key, ok, d.Size())
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package quickdict
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// The write-ahead log is a sequence of records, each written with a single
+// write call:
+//
+//	record: payload length (4 bytes) | payload CRC-32C (4 bytes) | payload
+//	payload: op count (uvarint) | ops
+//	op:      kind byte | key length (uvarint) | key [| value length (uvarint) | value]
+//
+// Integers in the record header are little endian. A record holds every op of
+// one call, so SetMany and DeleteMany are applied entirely or not at all on
+// recovery.
+const (
+	opSet    = 1
+	opDelete = 2
+
+	walHeaderSize = 8
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errBadRecord is wrapped by the errors for a record that cannot be
+// replayed and is not a torn write at the end of the log
+var errBadRecord = errors.New("quickdict: bad log record")
+
+// recordEncoder builds one log record from a batch of ops
+type recordEncoder[K comparable, V any] struct {
+	keys   quickmap.Codec[K]
+	values quickmap.Codec[V]
+	buf    []byte
+	field  []byte
+}
+
+// begin starts a record that will hold ops ops
+func (e *recordEncoder[K, V]) begin(ops int) {
+	e.buf = append(e.buf[:0], make([]byte, walHeaderSize)...)
+	e.buf = binary.AppendUvarint(e.buf, uint64(ops))
+}
+
+func (e *recordEncoder[K, V]) set(key K, value V) error {
+	if err := e.appendKey(opSet, key); err != nil {
+		return err
+	}
+	var err error
+	if e.field, err = e.values.Append(e.field[:0], value); err != nil {
+		return err
+	}
+	e.appendField()
+	return nil
+}
+
+func (e *recordEncoder[K, V]) delete(key K) error {
+	return e.appendKey(opDelete, key)
+}
+
+func (e *recordEncoder[K, V]) appendKey(kind byte, key K) error {
+	var err error
+	if e.field, err = e.keys.Append(e.field[:0], key); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, kind)
+	e.appendField()
+	return nil
+}
+
+func (e *recordEncoder[K, V]) appendField() {
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(e.field)))
+	e.buf = append(e.buf, e.field...)
+}
+
+// record fills in the header and returns the finished record
+func (e *recordEncoder[K, V]) record() []byte {
+	payload := e.buf[walHeaderSize:]
+	binary.LittleEndian.PutUint32(e.buf, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(e.buf[4:], crc32.Checksum(payload, crcTable))
+	return e.buf
+}
+
+// replayLog applies every valid record in the size bytes of r to d and
+// returns the length of the valid prefix of the log. It stops without error
+// at a record that is incomplete, or that fails its checksum and ends the
+// log, which is where a crash tears a write. A record that fails its checksum
+// with more of the log after it is damage to synced data, and is an error
+// wrapping errBadRecord.
+func replayLog[K comparable, V any](r io.Reader, size int64, d *QuickDict[K, V]) (int64, error) {
+	br := bufio.NewReader(r)
+	keys, values := d.data.KeyCodec(), d.data.ValueCodec()
+	var valid int64
+	var header [walHeaderSize]byte
+	var payload []byte
+	for {
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return valid, ignoreTorn(err)
+		}
+		length := binary.LittleEndian.Uint32(header[:])
+		if int64(length) > size-valid-walHeaderSize {
+			return valid, nil
+		}
+		payload = slices.Grow(payload[:0], int(length))[:length]
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return valid, ignoreTorn(err)
+		}
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:]) {
+			if end := valid + walHeaderSize + int64(length); end < size {
+				return valid, fmt.Errorf("%w: checksum mismatch at offset %d, with %d bytes after it", errBadRecord, valid, size-end)
+			}
+			return valid, nil
+		}
+		if err := applyRecord(payload, d, keys, values); err != nil {
+			return valid, err
+		}
+		valid += walHeaderSize + int64(length)
+	}
+}
+
+// ignoreTorn treats an early end of the log as its end
+func ignoreTorn(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	return err
+}
+
+// applyRecord decodes a whole record before applying any of it, so that a
+// record that fails to decode leaves d unchanged
+func applyRecord[K comparable, V any](payload []byte, d *QuickDict[K, V], keys quickmap.Codec[K], values quickmap.Codec[V]) error {
+	type op struct {
+		kind  byte
+		key   K
+		value V
+	}
+	count, n := binary.Uvarint(payload)
+	if n <= 0 || count > uint64(len(payload)) {
+		return fmt.Errorf("%w: invalid op count", errBadRecord)
+	}
+	payload = payload[n:]
+	ops := make([]op, 0, count)
+	for i := uint64(0); i < count; i++ {
+		if len(payload) == 0 {
+			return fmt.Errorf("%w: missing op", errBadRecord)
+		}
+		o := op{kind: payload[0]}
+		data, rest, err := walField(payload[1:])
+		if err != nil {
+			return err
+		}
+		if o.key, err = keys.Decode(data); err != nil {
+			return fmt.Errorf("quickdict: decoding logged key: %w", err)
+		}
+		switch o.kind {
+		case opSet:
+			if data, rest, err = walField(rest); err != nil {
+				return err
+			}
+			if o.value, err = values.Decode(data); err != nil {
+				return fmt.Errorf("quickdict: decoding logged value: %w", err)
+			}
+		case opDelete:
+		default:
+			return fmt.Errorf("%w: unknown op %d", errBadRecord, o.kind)
+		}
+		ops = append(ops, o)
+		payload = rest
+	}
+
+	for _, o := range ops {
+		if o.kind == opSet {
+			d.Set(o.key, o.value)
+		} else {
+			d.Delete(o.key)
+		}
+	}
+	return nil
+}
+
+func walField(data []byte) ([]byte, []byte, error) {
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n > uint64(len(data)-k) {
+		return nil, nil, fmt.Errorf("%w: truncated field", errBadRecord)
+	}
+	end := uint64(k) + n
+	return data[k:end], data[end:], nil
+}
@@ -0,0 +1,43 @@
+package quickdict
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+func TestWAL(t *testing.T) {
+	e := recordEncoder[string, int]{keys: quickmap.DefaultCodec[string](), values: quickmap.DefaultCodec[int]()}
+	var log bytes.Buffer
+	e.begin(2)
+	e.set("a", 1)
+	e.set("b", 2)
+	log.Write(e.record())
+	e.begin(1)
+	e.delete("a")
+	log.Write(e.record())
+	good := log.Len()
+
+	// Test that a record with a bad checksum ends the log
+	e.begin(1)
+	e.set("c", 3)
+	record := e.record()
+	record[len(record)-1] ^= 1
+	log.Write(record)
+
+	d := NewDict[string, int](nil)
+	valid, err := replayLog(bytes.NewReader(log.Bytes()), int64(log.Len()), d)
+	if err != nil || valid != int64(good) {
+		t.Errorf("replayLog = %d, %v; expected %d, nil", valid, err, good)
+	}
+	if value, exists := d.Get("b"); !exists || value != 2 || d.Size() != 1 {
+		t.Errorf("Get(\"b\") = %d, %t with Size() = %d; expected 2, true, 1", value, exists, d.Size())
+	}
+
+	// Test that a length running past the end of the log ends it
+	valid, err = replayLog(bytes.NewReader(log.Bytes()[:good+4]), int64(good+4), NewDict[string, int](nil))
+	if err != nil || valid != int64(good) {
+		t.Errorf("replayLog of a torn header = %d, %v; expected %d, nil", valid, err, good)
+	}
+}
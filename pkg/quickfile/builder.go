@@ -0,0 +1,199 @@
+package quickfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/marpit19/goquickmap/internal/hash"
+	"github.com/marpit19/goquickmap/internal/ioutil"
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// maxLoad is the largest share of slots a file fills, as a fraction of 4
+const maxLoad = 3
+
+// Builder collects entries in memory and writes them as a hash file. Fill it
+// from a quickset.QuickSet with AddKeys(set.All()) or from a
+// quickdict.QuickDict with AddAll(dict.All()).
+type Builder[V any] struct {
+	codec  quickmap.Codec[V]
+	values bool
+	index  map[string]int
+	keys   []string
+	// spans holds the start and end in encoded of each key's value
+	spans   [][2]int
+	encoded []byte
+}
+
+// NewSetBuilder creates and returns a Builder for a file opened with OpenSet
+func NewSetBuilder() *Builder[struct{}] {
+	return &Builder[struct{}]{index: make(map[string]int)}
+}
+
+// NewDictBuilder creates and returns a Builder for a file opened with
+// OpenDict. Values are encoded with codec; a nil codec selects
+// quickmap.DefaultCodec.
+func NewDictBuilder[V any](codec quickmap.Codec[V]) *Builder[V] {
+	if codec == nil {
+		codec = quickmap.DefaultCodec[V]()
+	}
+	return &Builder[V]{codec: codec, values: true, index: make(map[string]int)}
+}
+
+// Add adds a key-value pair, replacing the value of a key added before. The
+// value is ignored by a set builder.
+func (b *Builder[V]) Add(key string, value V) error {
+	start, end := len(b.encoded), len(b.encoded)
+	if b.values {
+		var err error
+		if b.encoded, err = b.codec.Append(b.encoded, value); err != nil {
+			b.encoded = b.encoded[:start]
+			return err
+		}
+		end = len(b.encoded)
+	}
+	if i, ok := b.index[key]; ok {
+		b.spans[i] = [2]int{start, end}
+		return nil
+	}
+	b.index[key] = len(b.keys)
+	b.keys = append(b.keys, key)
+	b.spans = append(b.spans, [2]int{start, end})
+	return nil
+}
+
+// AddAll adds every pair of all, stopping at the first encoding error
+func (b *Builder[V]) AddAll(all iter.Seq2[string, V]) error {
+	for key, value := range all {
+		if err := b.Add(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddKeys adds every key of keys with the zero value
+func (b *Builder[V]) AddKeys(keys iter.Seq[string]) error {
+	var zero V
+	for key := range keys {
+		if err := b.Add(key, zero); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns the number of keys added so far
+func (b *Builder[V]) Size() int {
+	return len(b.keys)
+}
+
+// WriteTo writes the hash file to w and returns the number of bytes written.
+// Every file is hashed with a new random seed.
+func (b *Builder[V]) WriteTo(w io.Writer) (int64, error) {
+	slotCount := uint64(1)
+	for slotCount*maxLoad/4 < uint64(len(b.keys)) {
+		slotCount *= 2
+	}
+	seed := hash.NewSeed()
+
+	// Place every key in the slot table, then checksum the table and the
+	// entries before anything is written, since the header holds the CRC
+	slots := make([]byte, slotCount*slotSize)
+	mask := slotCount - 1
+	offset := uint64(headerSize) + uint64(len(slots))
+	for i, key := range b.keys {
+		hashed := hash.String(key, seed)
+		slot := hashed & mask
+		for binary.LittleEndian.Uint64(slots[slot*slotSize+8:]) != 0 {
+			slot = (slot + 1) & mask
+		}
+		binary.LittleEndian.PutUint64(slots[slot*slotSize:], hashed)
+		binary.LittleEndian.PutUint64(slots[slot*slotSize+8:], offset)
+		offset += uint64(b.entrySize(i))
+	}
+	crc := crc32.Update(0, crcTable, slots)
+	var scratch []byte
+	for i := range b.keys {
+		scratch = b.appendEntry(scratch[:0], i)
+		crc = crc32.Update(crc, crcTable, scratch)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	header[4] = version
+	if b.values {
+		header[5] = flagValues
+	}
+	binary.LittleEndian.PutUint64(header[8:], uint64(len(b.keys)))
+	binary.LittleEndian.PutUint64(header[16:], slotCount)
+	binary.LittleEndian.PutUint64(header[24:], seed)
+	binary.LittleEndian.PutUint32(header[40:], crc32.Checksum(header[:40], crcTable))
+	binary.LittleEndian.PutUint32(header[44:], crc)
+
+	cw := &ioutil.CountingWriter{W: w}
+	bw := bufio.NewWriter(cw)
+	bw.Write(header)
+	bw.Write(slots)
+	for i := range b.keys {
+		scratch = b.appendEntry(scratch[:0], i)
+		if _, err := bw.Write(scratch); err != nil {
+			return cw.N, err
+		}
+	}
+	err := bw.Flush()
+	return cw.N, err
+}
+
+// WriteFile writes the hash file to path. The file is written under a
+// temporary name and renamed into place, so processes that have the old file
+// open keep reading it undisturbed.
+func (b *Builder[V]) WriteFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := b.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (b *Builder[V]) entrySize(i int) int {
+	n := uvarintLen(len(b.keys[i])) + len(b.keys[i])
+	if b.values {
+		length := b.spans[i][1] - b.spans[i][0]
+		n += uvarintLen(length) + length
+	}
+	return n
+}
+
+func (b *Builder[V]) appendEntry(buf []byte, i int) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b.keys[i])))
+	buf = append(buf, b.keys[i]...)
+	if b.values {
+		value := b.encoded[b.spans[i][0]:b.spans[i][1]]
+		buf = binary.AppendUvarint(buf, uint64(len(value)))
+		buf = append(buf, value...)
+	}
+	return buf
+}
+
+func uvarintLen(n int) int {
+	var buf [binary.MaxVarintLen64]byte
+	return len(binary.AppendUvarint(buf[:0], uint64(n)))
+}
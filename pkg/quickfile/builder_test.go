@@ -0,0 +1,53 @@
+package quickfile
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	// Test that adding a key again replaces its value
+	t.Run("Replace", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dict")
+		b := NewDictBuilder[string](nil)
+		b.Add("a", "first")
+		b.Add("b", "other")
+		b.Add("a", "second")
+		if b.Size() != 2 {
+			t.Errorf("Size() = %d, expected 2", b.Size())
+		}
+		if err := b.WriteFile(path); err != nil {
+			t.Fatalf("WriteFile returned %v, expected nil", err)
+		}
+		f, err := OpenDict[string](path, nil)
+		if err != nil {
+			t.Fatalf("OpenDict returned %v, expected nil", err)
+		}
+		defer f.Close()
+		if value, _ := f.Get("a"); value != "second" {
+			t.Errorf("Get(\"a\") = %q, expected \"second\"", value)
+		}
+	})
+
+	// Test an empty file
+	t.Run("Empty", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty")
+		var buf bytes.Buffer
+		n, err := NewSetBuilder().WriteTo(&buf)
+		if err != nil || n != int64(buf.Len()) {
+			t.Fatalf("WriteTo = %d, %v; expected %d, nil", n, err, buf.Len())
+		}
+		if err := NewSetBuilder().WriteFile(path); err != nil {
+			t.Fatalf("WriteFile returned %v, expected nil", err)
+		}
+		f, err := OpenSet(path)
+		if err != nil {
+			t.Fatalf("OpenSet returned %v, expected nil", err)
+		}
+		defer f.Close()
+		if f.Size() != 0 || f.Contains("") {
+			t.Errorf("Size() = %d and Contains(\"\") = %t, expected 0 and false", f.Size(), f.Contains(""))
+		}
+	})
+}
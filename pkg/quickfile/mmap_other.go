@@ -0,0 +1,22 @@
+//go:build !unix
+
+package quickfile
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the first size bytes of f into memory on platforms where
+// files are not memory-mapped
+func mapFile(f *os.File, size int) ([]byte, bool, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		return nil, false, err
+	}
+	return data, false, nil
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package quickfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the first size bytes of f read-only into memory
+func mapFile(f *os.File, size int) ([]byte, bool, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, false, &os.PathError{Op: "mmap", Path: f.Name(), Err: err}
+	}
+	return data, true, nil
+}
+
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,281 @@
+// Package quickfile provides read-only sets and dictionaries stored in hash
+// files, built once with a Builder and opened with OpenSet or OpenDict. An
+// open file is memory-mapped where the platform allows it, so many processes
+// can share one copy of a large lookup table, and lookups read keys and
+// values straight from the mapped bytes without allocating.
+package quickfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+
+	"github.com/marpit19/goquickmap/internal/hash"
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// A hash file is laid out as
+//
+//	header:  magic "GQMF" | version byte | flags byte | 2 reserved bytes |
+//	         entry count | slot count | seed | header CRC | body CRC
+//	slots:   slot count × (key hash | entry offset)
+//	entries: key length (uvarint) | key | value length (uvarint) | value
+//
+// Integers are little endian; the counts, seed, hashes and offsets take 8
+// bytes and the CRCs, which are CRC-32C, take 4. The header CRC covers the
+// bytes before it and the body CRC covers everything after the header. The
+// slots form an open-addressed table probed linearly from the key's hash,
+// with an offset of 0 marking an empty slot. Entries only carry values in
+// dictionary files.
+const (
+	magic      = "GQMF"
+	version    = 1
+	headerSize = 48
+	slotSize   = 16
+
+	// flagValues is set in dictionary files, whose entries carry values
+	flagValues = 1 << 0
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorrupt is returned, wrapped with details, when a file is truncated,
+// malformed or fails a checksum
+var ErrCorrupt = errors.New("quickfile: corrupt file")
+
+// file is an open hash file
+type file struct {
+	data  []byte
+	slots []byte
+	mask  uint64
+	count int
+	seed  uint64
+	// mapped is set when data must be unmapped rather than left to the GC
+	mapped bool
+}
+
+func open(path string, values bool) (*file, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < headerSize {
+		return nil, fmt.Errorf("%w: %s is too short", ErrCorrupt, path)
+	}
+	data, mapped, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	h := &file{data: data, mapped: mapped}
+	if err := h.parseHeader(values); err != nil {
+		h.close()
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	return h, nil
+}
+
+func (h *file) parseHeader(values bool) error {
+	header := h.data[:headerSize]
+	if string(header[:4]) != magic {
+		return fmt.Errorf("%w: not a quickfile hash file", ErrCorrupt)
+	}
+	if crc32.Checksum(header[:40], crcTable) != binary.LittleEndian.Uint32(header[40:]) {
+		return fmt.Errorf("%w: header checksum mismatch", ErrCorrupt)
+	}
+	if header[4] != version {
+		return fmt.Errorf("quickfile: unsupported file version %d", header[4])
+	}
+	switch hasValues := header[5]&flagValues != 0; {
+	case hasValues && !values:
+		return errors.New("quickfile: file holds a dictionary, expected a set")
+	case !hasValues && values:
+		return errors.New("quickfile: file holds a set, expected a dictionary")
+	}
+
+	count := binary.LittleEndian.Uint64(header[8:])
+	slots := binary.LittleEndian.Uint64(header[16:])
+	if slots == 0 || slots&(slots-1) != 0 || count >= slots {
+		return fmt.Errorf("%w: %d entries in %d slots", ErrCorrupt, count, slots)
+	}
+	if slots > uint64(len(h.data)-headerSize)/slotSize {
+		return fmt.Errorf("%w: slot table is truncated", ErrCorrupt)
+	}
+	h.count = int(count)
+	h.mask = slots - 1
+	h.seed = binary.LittleEndian.Uint64(header[24:])
+	h.slots = h.data[headerSize : headerSize+slots*slotSize]
+	return nil
+}
+
+// lookup returns the encoded value stored for key. The slice points into the
+// file's data and is empty for set files.
+func (h *file) lookup(key string) ([]byte, bool) {
+	if h.slots == nil {
+		return nil, false
+	}
+	hashed := hash.String(key, h.seed)
+	for i, probes := hashed&h.mask, uint64(0); probes <= h.mask; i, probes = (i+1)&h.mask, probes+1 {
+		slot := h.slots[i*slotSize : (i+1)*slotSize]
+		offset := binary.LittleEndian.Uint64(slot[8:])
+		if offset == 0 {
+			return nil, false
+		}
+		if binary.LittleEndian.Uint64(slot) != hashed {
+			continue
+		}
+		stored, rest, ok := field(h.data, offset)
+		if !ok || string(stored) != key {
+			continue
+		}
+		if h.data[5]&flagValues == 0 {
+			return nil, true
+		}
+		value, _, ok := field(rest, 0)
+		return value, ok
+	}
+	return nil, false
+}
+
+// field returns the length-prefixed bytes at offset in data and what follows
+// them. It reports false if they run past the end of data.
+func field(data []byte, offset uint64) ([]byte, []byte, bool) {
+	if offset >= uint64(len(data)) {
+		return nil, nil, false
+	}
+	data = data[offset:]
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n > uint64(len(data)-k) {
+		return nil, nil, false
+	}
+	end := uint64(k) + n
+	return data[k:end:end], data[end:], true
+}
+
+// verify checks the body checksum, reading the whole file
+func (h *file) verify() error {
+	if h.data == nil {
+		return errClosed
+	}
+	if crc32.Checksum(h.data[headerSize:], crcTable) != binary.LittleEndian.Uint32(h.data[44:]) {
+		return fmt.Errorf("%w: body checksum mismatch", ErrCorrupt)
+	}
+	return nil
+}
+
+func (h *file) close() error {
+	data := h.data
+	h.data, h.slots = nil, nil
+	if data == nil {
+		return errClosed
+	}
+	if h.mapped {
+		return unmapFile(data)
+	}
+	return nil
+}
+
+var errClosed = errors.New("quickfile: file already closed")
+
+// Set is a read-only set of strings stored in a hash file. It is safe for
+// concurrent use, but must not be used after Close.
+type Set struct {
+	f *file
+}
+
+// OpenSet opens a set file written by a Builder from NewSetBuilder. Only the
+// header is checked; Verify checks the rest.
+func OpenSet(path string) (*Set, error) {
+	f, err := open(path, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Set{f: f}, nil
+}
+
+// Contains checks if an element exists in the set
+func (s *Set) Contains(element string) bool {
+	_, ok := s.f.lookup(element)
+	return ok
+}
+
+// Size returns the number of elements in the set
+func (s *Set) Size() int {
+	return s.f.count
+}
+
+// Verify reads the whole file and checks it against its checksum
+func (s *Set) Verify() error {
+	return s.f.verify()
+}
+
+// Close unmaps the file
+func (s *Set) Close() error {
+	return s.f.close()
+}
+
+// Dict is a read-only dictionary with string keys stored in a hash file. It is
+// safe for concurrent use, but must not be used after Close.
+type Dict[V any] struct {
+	f     *file
+	codec quickmap.Codec[V]
+}
+
+// OpenDict opens a dictionary file written by a Builder from NewDictBuilder.
+// Values are decoded with codec, which must match the one the file was built
+// with; a nil codec selects quickmap.DefaultCodec. Only the header is
+// checked; Verify checks the rest.
+func OpenDict[V any](path string, codec quickmap.Codec[V]) (*Dict[V], error) {
+	if codec == nil {
+		codec = quickmap.DefaultCodec[V]()
+	}
+	f, err := open(path, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Dict[V]{f: f, codec: codec}, nil
+}
+
+// Get retrieves a value by key. It panics if the stored value cannot be
+// decoded, which means the codec does not match the file or the file is
+// corrupt.
+func (d *Dict[V]) Get(key string) (V, bool) {
+	data, ok := d.f.lookup(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	value, err := d.codec.Decode(data)
+	if err != nil {
+		panic(fmt.Sprintf("quickfile: decoding value for key %q: %v", key, err))
+	}
+	return value, true
+}
+
+// GetBytes returns the encoded value stored for key without decoding it. The
+// slice points into the mapped file: it must not be modified, and is only
+// valid until Close.
+func (d *Dict[V]) GetBytes(key string) ([]byte, bool) {
+	return d.f.lookup(key)
+}
+
+// Size returns the number of entries in the dictionary
+func (d *Dict[V]) Size() int {
+	return d.f.count
+}
+
+// Verify reads the whole file and checks it against its checksum
+func (d *Dict[V]) Verify() error {
+	return d.f.verify()
+}
+
+// Close unmaps the file
+func (d *Dict[V]) Close() error {
+	return d.f.close()
+}
@@ -0,0 +1,147 @@
+package quickfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickdict"
+	"github.com/marpit19/goquickmap/pkg/quickset"
+)
+
+func TestSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist")
+	s := quickset.NewSet[string](nil)
+	for i := 0; i < 10000; i++ {
+		s.Add("host" + strconv.Itoa(i))
+	}
+	b := NewSetBuilder()
+	b.AddKeys(s.All())
+	if err := b.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile returned %v, expected nil", err)
+	}
+
+	f, err := OpenSet(path)
+	if err != nil {
+		t.Fatalf("OpenSet returned %v, expected nil", err)
+	}
+	defer f.Close()
+
+	// Test Contains and Size against the source set
+	t.Run("Contains", func(t *testing.T) {
+		if f.Size() != 10000 {
+			t.Errorf("Size() = %d, expected 10000", f.Size())
+		}
+		for i := 0; i < 10000; i++ {
+			if key := "host" + strconv.Itoa(i); !f.Contains(key) {
+				t.Fatalf("Contains(%q) returned false, expected true", key)
+			}
+		}
+		if f.Contains("host10000") || f.Contains("") {
+			t.Errorf("Contains returned true for a missing element, expected false")
+		}
+		if err := f.Verify(); err != nil {
+			t.Errorf("Verify() returned %v, expected nil", err)
+		}
+	})
+
+	// Test that lookups do not allocate
+	t.Run("Allocations", func(t *testing.T) {
+		key := "host4242"
+		if allocs := testing.AllocsPerRun(100, func() { f.Contains(key) }); allocs != 0 {
+			t.Errorf("Contains allocated %v times per call, expected 0", allocs)
+		}
+	})
+
+	// Test that a set file cannot be opened as a dictionary
+	t.Run("Kind mismatch", func(t *testing.T) {
+		if _, err := OpenDict[int](path, nil); err == nil {
+			t.Errorf("OpenDict of a set file returned nil, expected an error")
+		}
+	})
+}
+
+func TestDict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog")
+	d := quickdict.NewDict[string, float64](nil)
+	for i := 0; i < 1000; i++ {
+		d.Set("sku"+strconv.Itoa(i), float64(i)/4)
+	}
+	b := NewDictBuilder[float64](nil)
+	if err := b.AddAll(d.All()); err != nil {
+		t.Fatalf("AddAll returned %v, expected nil", err)
+	}
+	if err := b.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile returned %v, expected nil", err)
+	}
+
+	f, err := OpenDict[float64](path, nil)
+	if err != nil {
+		t.Fatalf("OpenDict returned %v, expected nil", err)
+	}
+	if value, exists := f.Get("sku42"); !exists || value != 10.5 {
+		t.Errorf("Get(\"sku42\") = %v, %t; expected 10.5, true", value, exists)
+	}
+	if data, exists := f.GetBytes("sku1"); !exists || len(data) != 8 {
+		t.Errorf("GetBytes(\"sku1\") = %v, %t; expected 8 bytes, true", data, exists)
+	}
+	if _, exists := f.Get("sku1000"); exists || f.Size() != 1000 {
+		t.Errorf("Get(\"sku1000\") returned true with Size() = %d, expected false and 1000", f.Size())
+	}
+	if allocs := testing.AllocsPerRun(100, func() { f.Get("sku7") }); allocs != 0 {
+		t.Errorf("Get allocated %v times per call, expected 0", allocs)
+	}
+
+	// Test Close, including a second call
+	if err := f.Close(); err != nil {
+		t.Errorf("Close() returned %v, expected nil", err)
+	}
+	if _, exists := f.Get("sku42"); exists {
+		t.Errorf("Get after Close returned true, expected false")
+	}
+	if err := f.Close(); err == nil {
+		t.Errorf("second Close() returned nil, expected an error")
+	}
+}
+
+func TestCorruption(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "set")
+	b := NewSetBuilder()
+	for i := 0; i < 100; i++ {
+		b.Add(strconv.Itoa(i), struct{}{})
+	}
+	if err := b.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile returned %v, expected nil", err)
+	}
+	data, _ := os.ReadFile(path)
+
+	// Test a damaged header, a damaged body and a truncated file
+	cases := map[string][]byte{
+		"header":    flip(data, 20),
+		"body":      flip(data, len(data)-1),
+		"truncated": data[:headerSize+10],
+	}
+	for name, damaged := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			os.WriteFile(path, damaged, 0o644)
+			f, err := OpenSet(path)
+			if err == nil {
+				defer f.Close()
+				err = f.Verify()
+			}
+			if !errors.Is(err, ErrCorrupt) {
+				t.Errorf("opening and verifying a %s-damaged file returned %v, expected ErrCorrupt", name, err)
+			}
+		})
+	}
+}
+
+func flip(data []byte, i int) []byte {
+	damaged := append([]byte(nil), data...)
+	damaged[i] ^= 0xff
+	return damaged
+}
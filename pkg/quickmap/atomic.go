@@ -0,0 +1,282 @@
+package quickmap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AtomicQuickMap is a hash table for read-mostly workloads that is safe for
+// concurrent use and whose reads never block. Get loads the bucket array and
+// the head of a chain with atomic loads and walks nodes that are never
+// modified once published. Writers are serialised by a mutex and publish
+// changes copy-on-write: an insert prepends a new node, while an update or
+// delete rebuilds the part of the chain in front of the affected node. A
+// resize builds a complete new bucket array and swaps it in atomically, so
+// readers always see either the old table or the new one.
+type AtomicQuickMap[K comparable, V any] struct {
+	mu           sync.Mutex
+	table        atomic.Pointer[atomicTable[K, V]]
+	size         atomic.Int64
+	hasher       Hasher[K]
+	seed         uint64
+	shrinkFactor float64
+}
+
+type atomicTable[K comparable, V any] struct {
+	buckets []atomic.Pointer[atomicNode[K, V]]
+}
+
+// atomicNode is immutable once it is reachable from a bucket
+type atomicNode[K comparable, V any] struct {
+	key   K
+	value V
+	next  *atomicNode[K, V]
+}
+
+// AtomicStringMap is an AtomicQuickMap with string keys and untyped values, as returned by NewAtomic
+type AtomicStringMap = AtomicQuickMap[string, interface{}]
+
+// NewAtomic creates and returns a new AtomicQuickMap with string keys
+func NewAtomic(opts ...Option) *AtomicStringMap {
+	return NewAtomicMap[string, interface{}](nil, opts...)
+}
+
+// NewAtomicMap creates and returns a new AtomicQuickMap that hashes its keys
+// with hasher. A nil hasher behaves as described for NewMap. The map always
+// uses chained buckets, so WithBackend and WithIncrementalResize are ignored.
+func NewAtomicMap[K comparable, V any](hasher Hasher[K], opts ...Option) *AtomicQuickMap[K, V] {
+	o := newOptions(opts)
+	m := &AtomicQuickMap[K, V]{
+		hasher:       resolveHasher(hasher, o),
+		seed:         o.seed,
+		shrinkFactor: o.shrinkFactor,
+	}
+	m.table.Store(newAtomicTable[K, V](defaultInitialSize))
+	return m
+}
+
+func newAtomicTable[K comparable, V any](capacity int) *atomicTable[K, V] {
+	return &atomicTable[K, V]{buckets: make([]atomic.Pointer[atomicNode[K, V]], capacity)}
+}
+
+func (t *atomicTable[K, V]) bucket(h uint64) *atomic.Pointer[atomicNode[K, V]] {
+	return &t.buckets[h%uint64(len(t.buckets))]
+}
+
+// Get retrieves a value by key without taking a lock
+func (m *AtomicQuickMap[K, V]) Get(key K) (V, bool) {
+	h := m.hasher(key, m.seed)
+	for current := m.table.Load().bucket(h).Load(); current != nil; current = current.next {
+		if current.key == key {
+			return current.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert adds a new key-value pair to the map
+func (m *AtomicQuickMap[K, V]) Insert(key K, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.insertLocked(key, m.hasher(key, m.seed), value)
+}
+
+func (m *AtomicQuickMap[K, V]) insertLocked(key K, h uint64, value V) {
+	t := m.table.Load()
+	b := t.bucket(h)
+	head := b.Load()
+	n := &atomicNode[K, V]{key: key, value: value}
+	if head, ok := replaced(head, key, n); ok {
+		b.Store(head)
+		return
+	}
+	n.next = head
+	b.Store(n)
+
+	size := m.size.Add(1)
+	if float64(size)/float64(len(t.buckets)) > loadFactor {
+		capacity := len(t.buckets) * 2
+		for float64(size)/float64(capacity) > loadFactor {
+			capacity *= 2
+		}
+		m.rebuild(capacity)
+	}
+}
+
+// Delete removes a key-value pair from the map
+func (m *AtomicQuickMap[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteLocked(key, m.hasher(key, m.seed))
+	m.maybeShrinkLocked()
+}
+
+func (m *AtomicQuickMap[K, V]) deleteLocked(key K, h uint64) {
+	b := m.table.Load().bucket(h)
+	if head, ok := replaced(b.Load(), key, nil); ok {
+		b.Store(head)
+		m.size.Add(-1)
+	}
+}
+
+// Compute looks key up and calls f with its current value and whether it
+// exists, holding the write lock throughout, so the whole operation is atomic
+// with respect to other writers. Concurrent readers see the value from before
+// or after the call. If f returns true the key is stored with the value f
+// returns, otherwise it is deleted. Compute returns the key's value afterwards
+// and whether it is present. f must not use the map.
+func (m *AtomicQuickMap[K, V]) Compute(key K, f func(old V, exists bool) (V, bool)) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.hasher(key, m.seed)
+	var old V
+	exists := false
+	for current := m.table.Load().bucket(h).Load(); current != nil; current = current.next {
+		if current.key == key {
+			old, exists = current.value, true
+			break
+		}
+	}
+
+	value, keep := f(old, exists)
+	if keep {
+		m.insertLocked(key, h, value)
+		return value, true
+	}
+	if exists {
+		m.deleteLocked(key, h)
+		m.maybeShrinkLocked()
+	}
+	var zero V
+	return zero, false
+}
+
+// GetOrInsert returns the existing value for key if present. Otherwise it
+// inserts value and returns it. loaded reports whether the key was present.
+func (m *AtomicQuickMap[K, V]) GetOrInsert(key K, value V) (actual V, loaded bool) {
+	actual, _ = m.Compute(key, getOrInsert(value, &loaded))
+	return actual, loaded
+}
+
+// LoadAndDelete deletes key and returns its previous value, if any. loaded
+// reports whether the key was present.
+func (m *AtomicQuickMap[K, V]) LoadAndDelete(key K) (previous V, loaded bool) {
+	m.Compute(key, loadAndDelete(&previous, &loaded))
+	return previous, loaded
+}
+
+// Swap stores value for key and returns the previous value, if any. loaded
+// reports whether the key was present.
+func (m *AtomicQuickMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	m.Compute(key, swap(value, &previous, &loaded))
+	return previous, loaded
+}
+
+// CompareAndSwap stores new for key if the key is present and its value
+// equals old, and reports whether it did. It panics if V is not comparable.
+func (m *AtomicQuickMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	m.Compute(key, compareAndSwap(old, new, &swapped))
+	return swapped
+}
+
+// Update replaces the value of key with the result of f applied to it, and
+// reports whether the key was present
+func (m *AtomicQuickMap[K, V]) Update(key K, f func(old V) V) (updated bool) {
+	m.Compute(key, update(f, &updated))
+	return updated
+}
+
+// replaced returns a copy of the chain starting at head in which the node for
+// key is replaced by with, or unlinked if with is nil. Nodes after the
+// replaced one are shared with the original chain.
+func replaced[K comparable, V any](head *atomicNode[K, V], key K, with *atomicNode[K, V]) (*atomicNode[K, V], bool) {
+	if head == nil {
+		return nil, false
+	}
+	if head.key == key {
+		if with == nil {
+			return head.next, true
+		}
+		with.next = head.next
+		return with, true
+	}
+	rest, ok := replaced(head.next, key, with)
+	if !ok {
+		return head, false
+	}
+	return &atomicNode[K, V]{key: head.key, value: head.value, next: rest}, true
+}
+
+// Size returns the number of elements in the map
+func (m *AtomicQuickMap[K, V]) Size() int {
+	return int(m.size.Load())
+}
+
+// ForEach iterates over all key-value pairs in the map without taking a lock.
+// It walks the bucket array that was current when it started; writes made
+// during the walk may or may not be seen.
+func (m *AtomicQuickMap[K, V]) ForEach(f func(key K, value V)) {
+	t := m.table.Load()
+	for i := range t.buckets {
+		for current := t.buckets[i].Load(); current != nil; current = current.next {
+			f(current.key, current.value)
+		}
+	}
+}
+
+// InsertMany adds multiple key-value pairs to the map under a single lock
+func (m *AtomicQuickMap[K, V]) InsertMany(pairs map[K]V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Pre-allocate space if needed
+	if target := int(m.size.Load()) + len(pairs); target > int(float64(len(m.table.Load().buckets))*loadFactor) {
+		m.rebuild(minChainCapacity(target))
+	}
+	for k, v := range pairs {
+		m.insertLocked(k, m.hasher(k, m.seed), v)
+	}
+}
+
+// DeleteMany removes multiple keys from the map under a single lock
+func (m *AtomicQuickMap[K, V]) DeleteMany(keys []K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range keys {
+		m.deleteLocked(k, m.hasher(k, m.seed))
+	}
+	m.maybeShrinkLocked()
+}
+
+// Compact rebuilds the map at the smallest capacity that holds its current entries
+func (m *AtomicQuickMap[K, V]) Compact() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if capacity := minChainCapacity(int(m.size.Load())); capacity < len(m.table.Load().buckets) {
+		m.rebuild(capacity)
+	}
+}
+
+func (m *AtomicQuickMap[K, V]) maybeShrinkLocked() {
+	t := m.table.Load()
+	size := int(m.size.Load())
+	if m.shrinkFactor > 0 && float64(size) < m.shrinkFactor*float64(len(t.buckets)) {
+		if capacity := minChainCapacity(size); capacity < len(t.buckets) {
+			m.rebuild(capacity)
+		}
+	}
+}
+
+// rebuild copies every entry into fresh nodes in a new bucket array and
+// publishes it. The old nodes are left untouched for readers still walking them.
+func (m *AtomicQuickMap[K, V]) rebuild(capacity int) {
+	old := m.table.Load()
+	t := newAtomicTable[K, V](capacity)
+	for i := range old.buckets {
+		for current := old.buckets[i].Load(); current != nil; current = current.next {
+			b := t.bucket(m.hasher(current.key, m.seed))
+			b.Store(&atomicNode[K, V]{key: current.key, value: current.value, next: b.Load()})
+		}
+	}
+	m.table.Store(t)
+}
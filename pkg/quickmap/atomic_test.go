@@ -0,0 +1,166 @@
+package quickmap
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAtomicQuickMap(t *testing.T) {
+	// Test the sequential API
+	t.Run("Insert, Get and Delete", func(t *testing.T) {
+		m := NewAtomic()
+		m.Insert("key1", "value1")
+		m.Insert("key1", "new_value")
+		if value, exists := m.Get("key1"); !exists || value != "new_value" {
+			t.Errorf("Get(\"key1\") = %v, %t; expected \"new_value\", true", value, exists)
+		}
+		if m.Size() != 1 {
+			t.Errorf("Size() = %d after overwriting, expected 1", m.Size())
+		}
+		m.Delete("key1")
+		if _, exists := m.Get("key1"); exists {
+			t.Errorf("Get(\"key1\") returned true after deletion, expected false")
+		}
+	})
+
+	// Test copy-on-write updates in the middle of a chain
+	t.Run("Chain updates", func(t *testing.T) {
+		m := NewAtomicMap[int, int](func(key int, seed uint64) uint64 { return 0 })
+		for i := 0; i < 10; i++ {
+			m.Insert(i, i)
+		}
+		before := m.table.Load()
+		head := before.buckets[0].Load()
+		m.Insert(5, 50)
+		m.Delete(3)
+		for n, i := head, 9; n != nil; n, i = n.next, i-1 {
+			if n.key != i || n.value != i {
+				t.Errorf("Published node %d changed to %d = %d", i, n.key, n.value)
+			}
+		}
+		for i := 0; i < 10; i++ {
+			value, exists := m.Get(i)
+			expected := i
+			if i == 5 {
+				expected = 50
+			}
+			if i == 3 && exists {
+				t.Errorf("Get(3) returned true after deletion, expected false")
+			} else if i != 3 && (!exists || value != expected) {
+				t.Errorf("Get(%d) = %d, %t; expected %d, true", i, value, exists, expected)
+			}
+		}
+	})
+
+	// Test batch operations, resizing and shrinking
+	t.Run("InsertMany and DeleteMany", func(t *testing.T) {
+		m := NewAtomicMap[int, int](nil, WithShrinkFactor(0.1))
+		pairs := make(map[int]int)
+		keys := make([]int, 0, 10000)
+		for i := 0; i < 10000; i++ {
+			pairs[i] = i
+			keys = append(keys, i)
+		}
+		m.InsertMany(pairs)
+		peak := len(m.table.Load().buckets)
+		m.DeleteMany(keys[:9900])
+		if m.Size() != 100 {
+			t.Errorf("Size() = %d, expected 100", m.Size())
+		}
+		if len(m.table.Load().buckets) >= peak {
+			t.Errorf("Capacity = %d after DeleteMany, expected below %d", len(m.table.Load().buckets), peak)
+		}
+		count := 0
+		m.ForEach(func(key, value int) { count++ })
+		if count != 100 {
+			t.Errorf("ForEach visited %d entries, expected 100", count)
+		}
+		m.Compact()
+		if _, exists := m.Get(9999); !exists {
+			t.Errorf("Get(9999) returned false after Compact, expected true")
+		}
+	})
+
+	// Test readers racing writers and resizes; run with -race
+	t.Run("Parallel", func(t *testing.T) {
+		m := NewAtomicMap[int, int](nil)
+		var stop atomic.Bool
+		var readers sync.WaitGroup
+		for r := 0; r < 4; r++ {
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				for i := 0; !stop.Load(); i++ {
+					key := i % 4000
+					if value, exists := m.Get(key); exists && value%4000 != key {
+						t.Errorf("Get(%d) = %d, which was never written", key, value)
+						return
+					}
+				}
+			}()
+		}
+
+		var writers sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			writers.Add(1)
+			go func(w int) {
+				defer writers.Done()
+				for i := 0; i < 20000; i++ {
+					key := (w*1000 + i) % 4000
+					switch i % 3 {
+					case 0, 1:
+						m.Insert(key, key+4000*i)
+					case 2:
+						m.Delete(key)
+					}
+				}
+			}(w)
+		}
+		writers.Wait()
+		stop.Store(true)
+		readers.Wait()
+
+		count := 0
+		m.ForEach(func(key, value int) { count++ })
+		if count != m.Size() {
+			t.Errorf("ForEach visited %d entries, Size() = %d", count, m.Size())
+		}
+	})
+}
+
+func BenchmarkAtomicQuickMap(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.Run("AtomicQuickMap", func(b *testing.B) {
+		m := NewAtomic()
+		for _, key := range keys {
+			m.Insert(key, 0)
+		}
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				m.Get(keys[i%len(keys)])
+				i++
+			}
+		})
+	})
+
+	b.Run("sync.Map", func(b *testing.B) {
+		var m sync.Map
+		for _, key := range keys {
+			m.Store(key, 0)
+		}
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				m.Load(keys[i%len(keys)])
+				i++
+			}
+		})
+	})
+}
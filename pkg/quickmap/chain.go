@@ -0,0 +1,357 @@
+package quickmap
+
+import (
+	"math"
+	"time"
+)
+
+type node[K comparable, V any] struct {
+	key   K
+	value V
+	next  *node[K, V]
+}
+
+// chainTable is the Chaining backend: an array of buckets, each holding a
+// linked list of the entries that hash to it
+type chainTable[K comparable, V any] struct {
+	buckets []*node[K, V]
+	size    int
+	hash    func(K) uint64
+
+	// During an incremental resize, old holds the previous bucket array.
+	// Buckets below migrated have been moved into buckets; the rest are still
+	// live in old and are moved migrateStep at a time by each operation.
+	// migrateStep is at least resizeStep, and large enough that the migration
+	// ends before the table can grow again.
+	old         []*node[K, V]
+	migrated    int
+	resizeStep  int
+	migrateStep int
+
+	rehashStats
+}
+
+func newChainTable[K comparable, V any](initialCapacity int, hash func(K) uint64, resizeStep int) *chainTable[K, V] {
+	return &chainTable[K, V]{
+		buckets:    make([]*node[K, V], initialCapacity),
+		size:       0,
+		hash:       hash,
+		resizeStep: resizeStep,
+	}
+}
+
+// oldBucket returns the index of the unmigrated old bucket that holds h, or
+// -1 if h belongs to the current bucket array. A key is only ever stored in
+// one of the two arrays, so lookups check whichever this selects.
+func (t *chainTable[K, V]) oldBucket(h uint64) int {
+	if t.old == nil {
+		return -1
+	}
+	index := int(h % uint64(len(t.old)))
+	if index < t.migrated {
+		return -1
+	}
+	return index
+}
+
+// step moves up to migrateStep buckets of an incremental resize into the
+// current bucket array
+func (t *chainTable[K, V]) step() {
+	if t.old == nil {
+		return
+	}
+	start := time.Now()
+	for n := 0; n < t.migrateStep && t.migrated < len(t.old); n++ {
+		t.migrate(t.migrated)
+	}
+	if t.migrated == len(t.old) {
+		t.old = nil
+		t.migrated = 0
+	}
+	t.rehashTime += time.Since(start)
+}
+
+// finishResize completes an incremental resize in a single call
+func (t *chainTable[K, V]) finishResize() {
+	if t.old == nil {
+		return
+	}
+	start := time.Now()
+	for t.migrated < len(t.old) {
+		t.migrate(t.migrated)
+	}
+	t.old = nil
+	t.migrated = 0
+	t.rehashTime += time.Since(start)
+}
+
+func (t *chainTable[K, V]) migrate(index int) {
+	bucket := t.old[index]
+	for bucket != nil {
+		newIndex := t.hash(bucket.key) % uint64(len(t.buckets))
+		next := bucket.next
+		bucket.next = t.buckets[newIndex]
+		t.buckets[newIndex] = bucket
+		bucket = next
+	}
+	t.old[index] = nil
+	t.migrated = index + 1
+}
+
+func (t *chainTable[K, V]) insert(key K, h uint64, value V) {
+	t.step()
+	index := h % uint64(len(t.buckets))
+	newNode := &node[K, V]{key: key, value: value}
+
+	if oldIndex := t.oldBucket(h); oldIndex >= 0 {
+		// The key's bucket has not been migrated yet, so it stays in old
+		for current := t.old[oldIndex]; current != nil; current = current.next {
+			if current.key == key {
+				current.value = value
+				return
+			}
+		}
+		newNode.next = t.old[oldIndex]
+		t.old[oldIndex] = newNode
+	} else if t.buckets[index] == nil {
+		t.buckets[index] = newNode
+	} else {
+		current := t.buckets[index]
+		for current.next != nil {
+			if current.key == key {
+				current.value = value
+				return
+			}
+			current = current.next
+		}
+		if current.key == key {
+			current.value = value
+			return
+		}
+		current.next = newNode
+	}
+	t.size++
+
+	if float64(t.size)/float64(len(t.buckets)) > loadFactor {
+		t.resize(t.size * 2)
+	}
+}
+
+func (t *chainTable[K, V]) get(key K, h uint64) (V, bool) {
+	t.step()
+	var current *node[K, V]
+	if oldIndex := t.oldBucket(h); oldIndex >= 0 {
+		current = t.old[oldIndex]
+	} else {
+		current = t.buckets[h%uint64(len(t.buckets))]
+	}
+
+	for current != nil {
+		if current.key == key {
+			return current.value, true
+		}
+		current = current.next
+	}
+
+	var zero V
+	return zero, false
+}
+
+func (t *chainTable[K, V]) remove(key K, h uint64) {
+	t.step()
+	buckets := t.buckets
+	index := h % uint64(len(buckets))
+	if oldIndex := t.oldBucket(h); oldIndex >= 0 {
+		buckets, index = t.old, uint64(oldIndex)
+	}
+	if buckets[index] == nil {
+		return
+	}
+
+	if buckets[index].key == key {
+		buckets[index] = buckets[index].next
+		t.size--
+		return
+	}
+
+	current := buckets[index]
+	for current.next != nil {
+		if current.next.key == key {
+			current.next = current.next.next
+			t.size--
+			return
+		}
+
+		current = current.next
+	}
+}
+
+func (t *chainTable[K, V]) compute(key K, h uint64, f func(old V, exists bool) (V, bool)) (V, bool) {
+	t.step()
+	buckets := t.buckets
+	index := h % uint64(len(buckets))
+	if oldIndex := t.oldBucket(h); oldIndex >= 0 {
+		buckets, index = t.old, uint64(oldIndex)
+	}
+
+	// link points at whichever pointer refers to current, so the node can be
+	// unlinked without walking the chain again
+	link := &buckets[index]
+	for current := *link; current != nil; current = *link {
+		if current.key == key {
+			value, keep := f(current.value, true)
+			if !keep {
+				*link = current.next
+				t.size--
+				var zero V
+				return zero, false
+			}
+			current.value = value
+			return value, true
+		}
+		link = &current.next
+	}
+
+	var zero V
+	value, keep := f(zero, false)
+	if !keep {
+		return zero, false
+	}
+	*link = &node[K, V]{key: key, value: value}
+	t.size++
+	if float64(t.size)/float64(len(t.buckets)) > loadFactor {
+		t.resize(t.size * 2)
+	}
+	return value, true
+}
+
+func (t *chainTable[K, V]) removeFunc(f func(key K, value V) bool) {
+	if t.old != nil {
+		for i := t.migrated; i < len(t.old); i++ {
+			t.removeFromChain(&t.old[i], f)
+		}
+	}
+	for i := range t.buckets {
+		t.removeFromChain(&t.buckets[i], f)
+	}
+}
+
+// removeFromChain unlinks every node in the chain at link for which f returns true
+func (t *chainTable[K, V]) removeFromChain(link **node[K, V], f func(key K, value V) bool) {
+	for current := *link; current != nil; current = *link {
+		if f(current.key, current.value) {
+			*link = current.next
+			t.size--
+		} else {
+			link = &current.next
+		}
+	}
+}
+
+func (t *chainTable[K, V]) len() int {
+	return t.size
+}
+
+func (t *chainTable[K, V]) capacity() int {
+	return len(t.buckets)
+}
+
+// forEach completes any incremental resize first, so that lookups made by f
+// cannot migrate entries into buckets it has yet to visit
+func (t *chainTable[K, V]) forEach(f func(key K, value V) bool) {
+	t.finishResize()
+	for _, bucket := range t.buckets {
+		current := bucket
+		for current != nil {
+			if !f(current.key, current.value) {
+				return
+			}
+			current = current.next
+		}
+	}
+}
+
+func (t *chainTable[K, V]) clone() table[K, V] {
+	c := *t
+	c.buckets = cloneBuckets(t.buckets)
+	if t.old != nil {
+		c.old = cloneBuckets(t.old)
+	}
+	return &c
+}
+
+// cloneBuckets copies every chain in buckets, preserving the order of each
+func cloneBuckets[K comparable, V any](buckets []*node[K, V]) []*node[K, V] {
+	c := make([]*node[K, V], len(buckets))
+	for i, bucket := range buckets {
+		link := &c[i]
+		for current := bucket; current != nil; current = current.next {
+			*link = &node[K, V]{key: current.key, value: current.value}
+			link = &(*link).next
+		}
+	}
+	return c
+}
+
+func (t *chainTable[K, V]) reserve(n int) {
+	if n > int(float64(len(t.buckets))*loadFactor) {
+		t.resize(n)
+	}
+}
+
+func (t *chainTable[K, V]) compact() {
+	t.finishResize()
+	newCapacity := minChainCapacity(t.size)
+	if newCapacity >= len(t.buckets) {
+		return
+	}
+	defer t.rehashed(time.Now())
+	t.rehash(newCapacity)
+}
+
+// minChainCapacity returns the smallest bucket count that holds n entries
+// within the load factor
+func minChainCapacity(n int) int {
+	return max(1, int(math.Ceil(float64(n)/loadFactor)))
+}
+
+// resize increases the size of the hash table and reshases all the elements.
+// In incremental mode it only swaps in the new bucket array and leaves the
+// rehashing to later operations. A migration still in progress is finished
+// first; with migrateStep sized as below, that only happens when reserve
+// grows the table ahead of the inserts.
+func (t *chainTable[K, V]) resize(targetSize int) {
+	t.finishResize()
+	defer t.rehashed(time.Now())
+	newCapacity := len(t.buckets) * 2
+	for newCapacity < targetSize {
+		newCapacity *= 2
+	}
+
+	if t.resizeStep > 0 {
+		t.old = t.buckets
+		t.buckets = make([]*node[K, V], newCapacity)
+		// Every insert steps once, so spread the old buckets over the inserts
+		// that can be made before the new array passes the load factor
+		inserts := max(1, int(float64(newCapacity)*loadFactor)-t.size)
+		t.migrateStep = max(t.resizeStep, (len(t.old)+inserts-1)/inserts)
+		return
+	}
+
+	t.rehash(newCapacity)
+}
+
+// rehash moves every node into a new array of newCapacity buckets
+func (t *chainTable[K, V]) rehash(newCapacity int) {
+	newBuckets := make([]*node[K, V], newCapacity)
+	for _, bucket := range t.buckets {
+		for bucket != nil {
+			index := t.hash(bucket.key) % uint64(newCapacity)
+			next := bucket.next
+			bucket.next = newBuckets[index]
+			newBuckets[index] = bucket
+			bucket = next
+		}
+	}
+	t.buckets = newBuckets
+}
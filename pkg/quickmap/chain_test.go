@@ -0,0 +1,120 @@
+package quickmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIncrementalResize(t *testing.T) {
+	// Test that crossing the load factor starts a migration instead of
+	// rehashing every bucket
+	t.Run("Bounded step", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 1024, WithIncrementalResize(4))
+		ct := m.t.(*chainTable[int, int])
+		for i := 0; i < 768; i++ {
+			m.Insert(i, i)
+		}
+		if ct.old != nil {
+			t.Fatalf("Migration started before the load factor was exceeded")
+		}
+		m.Insert(768, 768)
+		if ct.old == nil || len(ct.buckets) != 2048 {
+			t.Fatalf("Insert past the load factor did not start a migration to 2048 buckets")
+		}
+		if ct.migrated != 0 {
+			t.Errorf("Insert that started the migration moved %d buckets, expected 0", ct.migrated)
+		}
+		m.Get(0)
+		if ct.migrated != 4 {
+			t.Errorf("Get moved %d buckets, expected 4", ct.migrated)
+		}
+		for i := 0; i <= 768; i++ {
+			if value, exists := m.Get(i); !exists || value != i {
+				t.Errorf("Get(%d) = %d, %t during migration; expected %d, true", i, value, exists, i)
+			}
+		}
+		for ct.old != nil {
+			m.Get(-1)
+		}
+		if m.Size() != 769 {
+			t.Errorf("Size() = %d after migration, expected 769", m.Size())
+		}
+	})
+
+	// Test ForEach while both bucket arrays are live
+	t.Run("ForEach during migration", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 16, WithIncrementalResize(1))
+		for i := 0; i < 13; i++ {
+			m.Insert(i, i)
+		}
+		m.Get(0)
+		if ct := m.t.(*chainTable[int, int]); ct.old == nil || ct.migrated == 0 {
+			t.Fatalf("Expected a partially migrated table")
+		}
+		seen := make(map[int]bool)
+		m.ForEach(func(key, value int) {
+			if seen[key] {
+				t.Errorf("ForEach visited %d twice", key)
+			}
+			seen[key] = true
+		})
+		if len(seen) != 13 {
+			t.Errorf("ForEach visited %d entries, expected 13", len(seen))
+		}
+	})
+
+	// Test that a migration always finishes before the table grows again, so
+	// that resize never has old buckets left to move in one call
+	t.Run("Finished before growth", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 1, WithIncrementalResize(1))
+		ct := m.t.(*chainTable[int, int])
+		inserts := 100000
+		if debug {
+			// Validate walks the whole table after every insert
+			inserts = 5000
+		}
+		for i := 0; i < inserts; i++ {
+			// An insert of a new key steps once before any growth it causes
+			if float64(ct.size+1)/float64(len(ct.buckets)) > loadFactor && ct.old != nil {
+				if left := len(ct.old) - ct.migrated; left > ct.migrateStep {
+					t.Fatalf("Insert(%d) grows the table with %d old buckets left, more than a step of %d", i, left, ct.migrateStep)
+				}
+			}
+			m.Insert(i, i)
+		}
+		if ct.rebuilds() < 10 {
+			t.Errorf("Table grew %d times, expected at least 10", ct.rebuilds())
+		}
+	})
+
+	// Test random operations against the built-in map
+	t.Run("Random operations", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		m := NewMapWithCapacity[int, int](nil, 1, WithIncrementalResize(1))
+		model := make(map[int]int)
+		ops, keys := 200000, 20000
+		if debug {
+			ops, keys = 10000, 1000
+		}
+		for i := 0; i < ops; i++ {
+			key := rng.Intn(keys)
+			switch rng.Intn(4) {
+			case 0, 1:
+				m.Insert(key, i)
+				model[key] = i
+			case 2:
+				m.Delete(key)
+				delete(model, key)
+			case 3:
+				value, exists := m.Get(key)
+				expected, expectedExists := model[key]
+				if exists != expectedExists || value != expected {
+					t.Fatalf("Get(%d) = %d, %t; expected %d, %t", key, value, exists, expected, expectedExists)
+				}
+			}
+		}
+		if m.Size() != len(model) {
+			t.Errorf("Size() = %d, expected %d", m.Size(), len(model))
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package quickmap
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Codec converts keys or values of type T to and from the bytes stored in a
+// snapshot by WriteTo and ReadFrom
+type Codec[T any] interface {
+	// Append appends the encoding of value to buf and returns the extended buffer
+	Append(buf []byte, value T) ([]byte, error)
+	// Decode parses an encoding produced by Append. data is only valid for
+	// the duration of the call.
+	Decode(data []byte) (T, error)
+}
+
+// DefaultCodec returns the Codec used when none is given with WithKeyCodec or
+// WithValueCodec. Types implementing encoding.BinaryMarshaler, with a pointer
+// implementing encoding.BinaryUnmarshaler, use those methods. Otherwise
+// strings and byte slices are stored as they are, integers as varints,
+// floats and bools in fixed width and empty structs as nothing; any other
+// type is encoded as JSON, so an interface value decodes as the type
+// encoding/json chooses for it.
+func DefaultCodec[T any]() Codec[T] {
+	return defaultCodec[T]{}
+}
+
+type defaultCodec[T any] struct{}
+
+func (defaultCodec[T]) Append(buf []byte, value T) ([]byte, error) {
+	if bm, ok := any(value).(encoding.BinaryMarshaler); ok {
+		if _, ok := any(&value).(encoding.BinaryUnmarshaler); ok {
+			data, err := bm.MarshalBinary()
+			return append(buf, data...), err
+		}
+	}
+	v := reflect.ValueOf(&value).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		return append(buf, v.String()...), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return binary.AppendUvarint(buf, v.Uint()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float())), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append(buf, v.Bytes()...), nil
+		}
+	case reflect.Struct:
+		if v.Type().Size() == 0 {
+			return buf, nil
+		}
+	}
+	data, err := json.Marshal(value)
+	return append(buf, data...), err
+}
+
+func (c defaultCodec[T]) Decode(data []byte) (T, error) {
+	var value T
+	if ok, err := decodeBasic(&value, data); ok {
+		return value, err
+	}
+	return c.decode(data)
+}
+
+// decodeBasic decodes the most common value types without reflection, so
+// that the value need not escape to the heap. It reports false for any other
+// type, including named types.
+func decodeBasic(p any, data []byte) (bool, error) {
+	switch p := p.(type) {
+	case *string:
+		*p = string(data)
+	case *int:
+		n, k := binary.Varint(data)
+		if k != len(data) || n != int64(int(n)) {
+			return true, errInvalidEncoding(*p)
+		}
+		*p = int(n)
+	case *int64:
+		n, k := binary.Varint(data)
+		if k != len(data) {
+			return true, errInvalidEncoding(*p)
+		}
+		*p = n
+	case *uint64:
+		n, k := binary.Uvarint(data)
+		if k != len(data) {
+			return true, errInvalidEncoding(*p)
+		}
+		*p = n
+	case *float64:
+		if len(data) != 8 {
+			return true, errInvalidEncoding(*p)
+		}
+		*p = math.Float64frombits(binary.LittleEndian.Uint64(data))
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (defaultCodec[T]) decode(data []byte) (T, error) {
+	var value T
+	if bu, ok := any(&value).(encoding.BinaryUnmarshaler); ok {
+		if _, ok := any(value).(encoding.BinaryMarshaler); ok {
+			err := bu.UnmarshalBinary(data)
+			return value, err
+		}
+	}
+	v := reflect.ValueOf(&value).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(data))
+		return value, nil
+	case reflect.Bool:
+		if len(data) != 1 || data[0] > 1 {
+			return value, errInvalidEncoding(value)
+		}
+		v.SetBool(data[0] == 1)
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, k := binary.Varint(data)
+		if k != len(data) || v.OverflowInt(n) {
+			return value, errInvalidEncoding(value)
+		}
+		v.SetInt(n)
+		return value, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, k := binary.Uvarint(data)
+		if k != len(data) || v.OverflowUint(n) {
+			return value, errInvalidEncoding(value)
+		}
+		v.SetUint(n)
+		return value, nil
+	case reflect.Float32:
+		if len(data) != 4 {
+			return value, errInvalidEncoding(value)
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+		return value, nil
+	case reflect.Float64:
+		if len(data) != 8 {
+			return value, errInvalidEncoding(value)
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+		return value, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte(nil), data...))
+			return value, nil
+		}
+	case reflect.Struct:
+		if v.Type().Size() == 0 {
+			if len(data) != 0 {
+				return value, errInvalidEncoding(value)
+			}
+			return value, nil
+		}
+	}
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+func errInvalidEncoding(value any) error {
+	return fmt.Errorf("quickmap: invalid %T encoding", value)
+}
+
+// codecFor returns the codec passed to the option named name, or DefaultCodec
+// if there was none. It panics if the codec is for a type other than T.
+func codecFor[T any](c any, name string) Codec[T] {
+	if c == nil {
+		return DefaultCodec[T]()
+	}
+	if typed, ok := c.(Codec[T]); ok {
+		return typed
+	}
+	panic(fmt.Sprintf("quickmap: %s codec %T does not encode %v", name, c, reflect.TypeFor[T]()))
+}
+
+// KeyCodec returns the Codec the map uses for keys in snapshots
+func (m *QuickMap[K, V]) KeyCodec() Codec[K] {
+	if m.keyCodec == nil {
+		return DefaultCodec[K]()
+	}
+	return m.keyCodec
+}
+
+// ValueCodec returns the Codec the map uses for values in snapshots
+func (m *QuickMap[K, V]) ValueCodec() Codec[V] {
+	if m.valueCodec == nil {
+		return DefaultCodec[V]()
+	}
+	return m.valueCodec
+}
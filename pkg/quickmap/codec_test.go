@@ -0,0 +1,52 @@
+package quickmap
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDefaultCodec(t *testing.T) {
+	type label string
+	type point struct{ X, Y int }
+
+	// Test a round trip for each kind of type
+	checkRoundTrip(t, "text")
+	checkRoundTrip(t, label("named"))
+	checkRoundTrip(t, true)
+	checkRoundTrip(t, int8(-128))
+	checkRoundTrip(t, math.MinInt64)
+	checkRoundTrip(t, uint64(math.MaxUint64))
+	checkRoundTrip(t, float32(1.5))
+	checkRoundTrip(t, math.Inf(-1))
+	checkRoundTrip(t, []byte{0, 1, 2})
+	checkRoundTrip(t, struct{}{})
+	checkRoundTrip(t, time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC))
+	checkRoundTrip(t, point{3, -4})
+
+	// Test that malformed encodings are rejected
+	if _, err := DefaultCodec[int8]().Decode([]byte{0x80, 0x02}); err == nil {
+		t.Errorf("Decode of an overflowing int8 returned nil, expected an error")
+	}
+	if _, err := DefaultCodec[bool]().Decode([]byte{2}); err == nil {
+		t.Errorf("Decode of a bool byte 2 returned nil, expected an error")
+	}
+	if _, err := DefaultCodec[float64]().Decode([]byte{1, 2, 3}); err == nil {
+		t.Errorf("Decode of 3 bytes as float64 returned nil, expected an error")
+	}
+}
+
+func checkRoundTrip[T any](t *testing.T, value T) {
+	t.Helper()
+	c := DefaultCodec[T]()
+	data, err := c.Append([]byte("prefix"), value)
+	if err != nil || string(data[:6]) != "prefix" {
+		t.Errorf("Append(%v) = %q, %v; expected the prefix kept and nil", value, data, err)
+		return
+	}
+	got, err := c.Decode(data[6:])
+	if err != nil || !reflect.DeepEqual(got, value) {
+		t.Errorf("Decode(Append(%v)) = %v, %v; expected %v, nil", value, got, err, value)
+	}
+}
@@ -0,0 +1,152 @@
+package quickmap
+
+// Compute looks key up once and calls f with its current value and whether it
+// exists. If f returns true the key is stored with the value f returns,
+// otherwise it is deleted. Compute returns the key's value afterwards and
+// whether it is present. f must not modify the map.
+func (m *QuickMap[K, V]) Compute(key K, f func(old V, exists bool) (V, bool)) (V, bool) {
+	return m.computeNotify(key, f, nil)
+}
+
+// computeNotify is Compute, also recording metrics and reporting the change
+// to any listeners. unchanged, if set, reports after f has run whether f
+// left the map as it was, so that no event is sent.
+func (m *QuickMap[K, V]) computeNotify(key K, f func(old V, exists bool) (V, bool), unchanged func() bool) (V, bool) {
+	before := m.state()
+	if m.listeners == nil {
+		value, ok := m.compute(key, f)
+		m.record(before, ok)
+		return value, ok
+	}
+
+	capacity := m.t.capacity()
+	var old V
+	var existed bool
+	value, ok := m.compute(key, func(current V, exists bool) (V, bool) {
+		old, existed = current, exists
+		return f(current, exists)
+	})
+	m.record(before, ok)
+	switch {
+	case unchanged != nil && unchanged():
+	case ok:
+		m.notifyInsert(key, value, old, existed, capacity)
+	default:
+		m.notifyDelete(key, old, existed, capacity)
+	}
+	return value, ok
+}
+
+func (m *QuickMap[K, V]) compute(key K, f func(old V, exists bool) (V, bool)) (V, bool) {
+	h := m.hash(key)
+	if m.walkers > 0 {
+		// As in Insert, only a change to the table's layout needs a copy
+		if old, ok := m.t.get(key, h); ok {
+			value, keep := f(old, true)
+			if keep {
+				value, _ = m.t.compute(key, h, func(V, bool) (V, bool) { return value, true })
+				m.checkInvariants()
+				return value, true
+			}
+			m.detachForRemove()
+			m.t.remove(key, h)
+			m.maybeShrink()
+			m.checkInvariants()
+			var zero V
+			return zero, false
+		}
+		m.detach()
+	}
+	value, ok := m.t.compute(key, h, f)
+	if !ok {
+		m.maybeShrink()
+	}
+	m.checkInvariants()
+	return value, ok
+}
+
+// GetOrInsert returns the existing value for key if present. Otherwise it
+// inserts value and returns it. loaded reports whether the key was present.
+func (m *QuickMap[K, V]) GetOrInsert(key K, value V) (actual V, loaded bool) {
+	actual, _ = m.computeNotify(key, getOrInsert(value, &loaded), func() bool { return loaded })
+	return actual, loaded
+}
+
+// LoadAndDelete deletes key and returns its previous value, if any. loaded
+// reports whether the key was present.
+func (m *QuickMap[K, V]) LoadAndDelete(key K) (previous V, loaded bool) {
+	m.Compute(key, loadAndDelete(&previous, &loaded))
+	return previous, loaded
+}
+
+// Swap stores value for key and returns the previous value, if any. loaded
+// reports whether the key was present.
+func (m *QuickMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	m.Compute(key, swap(value, &previous, &loaded))
+	return previous, loaded
+}
+
+// CompareAndSwap stores new for key if the key is present and its value
+// equals old, and reports whether it did. Values are compared as interfaces,
+// so CompareAndSwap panics if V is not comparable.
+func (m *QuickMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	m.computeNotify(key, compareAndSwap(old, new, &swapped), func() bool { return !swapped })
+	return swapped
+}
+
+// Update replaces the value of key with the result of f applied to it, and
+// reports whether the key was present. Absent keys are left absent.
+func (m *QuickMap[K, V]) Update(key K, f func(old V) V) (updated bool) {
+	m.Compute(key, update(f, &updated))
+	return updated
+}
+
+// The helpers below build the Compute callbacks behind the compound
+// operations, so every map type implements them with the same semantics.
+
+func getOrInsert[V any](value V, loaded *bool) func(V, bool) (V, bool) {
+	return func(old V, exists bool) (V, bool) {
+		*loaded = exists
+		if exists {
+			return old, true
+		}
+		return value, true
+	}
+}
+
+func loadAndDelete[V any](previous *V, loaded *bool) func(V, bool) (V, bool) {
+	return func(old V, exists bool) (V, bool) {
+		*previous, *loaded = old, exists
+		return old, false
+	}
+}
+
+func swap[V any](value V, previous *V, loaded *bool) func(V, bool) (V, bool) {
+	return func(old V, exists bool) (V, bool) {
+		*previous, *loaded = old, exists
+		return value, true
+	}
+}
+
+func compareAndSwap[V any](old, new V, swapped *bool) func(V, bool) (V, bool) {
+	return func(current V, exists bool) (V, bool) {
+		if !exists {
+			return current, false
+		}
+		if any(current) != any(old) {
+			return current, true
+		}
+		*swapped = true
+		return new, true
+	}
+}
+
+func update[V any](f func(V) V, updated *bool) func(V, bool) (V, bool) {
+	return func(old V, exists bool) (V, bool) {
+		if !exists {
+			return old, false
+		}
+		*updated = true
+		return f(old), true
+	}
+}
@@ -0,0 +1,128 @@
+package quickmap
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	configs := map[string][]Option{
+		"incremental": {WithIncrementalResize(1)},
+	}
+	for _, backend := range backends {
+		configs[backend.String()] = []Option{WithBackend(backend)}
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			m := NewMapWithCapacity[string, int](nil, 1, opts...)
+
+			// Compute inserts, updates and deletes
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i)
+				if value, ok := m.Compute(key, func(old int, exists bool) (int, bool) {
+					if exists {
+						t.Errorf("Compute(%q) called with exists = true for a new key", key)
+					}
+					return i, true
+				}); !ok || value != i {
+					t.Errorf("Compute(%q) = %d, %t; expected %d, true", key, value, ok, i)
+				}
+			}
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i)
+				m.Compute(key, func(old int, exists bool) (int, bool) {
+					return old * 2, old%2 == 0
+				})
+			}
+			if m.Size() != 500 {
+				t.Errorf("Size() = %d after Compute deleted odd values, expected 500", m.Size())
+			}
+			if value, exists := m.Get("10"); !exists || value != 20 {
+				t.Errorf("Get(\"10\") = %d, %t; expected 20, true", value, exists)
+			}
+			if _, ok := m.Compute("missing", func(old int, exists bool) (int, bool) { return 0, false }); ok || m.Size() != 500 {
+				t.Errorf("Compute with keep = false on a missing key changed the map")
+			}
+
+			// GetOrInsert
+			if actual, loaded := m.GetOrInsert("10", 1); !loaded || actual != 20 {
+				t.Errorf("GetOrInsert(\"10\") = %d, %t; expected 20, true", actual, loaded)
+			}
+			if actual, loaded := m.GetOrInsert("new", 1); loaded || actual != 1 {
+				t.Errorf("GetOrInsert(\"new\") = %d, %t; expected 1, false", actual, loaded)
+			}
+
+			// LoadAndDelete
+			if previous, loaded := m.LoadAndDelete("new"); !loaded || previous != 1 {
+				t.Errorf("LoadAndDelete(\"new\") = %d, %t; expected 1, true", previous, loaded)
+			}
+			if _, loaded := m.LoadAndDelete("new"); loaded {
+				t.Errorf("LoadAndDelete(\"new\") returned true for a deleted key")
+			}
+
+			// Swap
+			if previous, loaded := m.Swap("10", 5); !loaded || previous != 20 {
+				t.Errorf("Swap(\"10\") = %d, %t; expected 20, true", previous, loaded)
+			}
+			if _, loaded := m.Swap("swapped", 5); loaded || m.Size() != 501 {
+				t.Errorf("Swap(\"swapped\") = %t with Size() = %d; expected false, 501", loaded, m.Size())
+			}
+
+			// CompareAndSwap
+			if m.CompareAndSwap("10", 4, 6) {
+				t.Errorf("CompareAndSwap(\"10\", 4, 6) = true, expected false")
+			}
+			if !m.CompareAndSwap("10", 5, 6) {
+				t.Errorf("CompareAndSwap(\"10\", 5, 6) = false, expected true")
+			}
+			if m.CompareAndSwap("missing", 0, 1) || m.Size() != 501 {
+				t.Errorf("CompareAndSwap on a missing key changed the map")
+			}
+
+			// Update
+			if !m.Update("10", func(old int) int { return old + 1 }) {
+				t.Errorf("Update(\"10\") = false, expected true")
+			}
+			if value, _ := m.Get("10"); value != 7 {
+				t.Errorf("Get(\"10\") = %d after Update, expected 7", value)
+			}
+			if m.Update("missing", func(old int) int { return old }) || m.Size() != 501 {
+				t.Errorf("Update on a missing key changed the map")
+			}
+		})
+	}
+
+	// Compound operations on the concurrent maps are atomic; run with -race
+	t.Run("Concurrent", func(t *testing.T) {
+		maps := map[string]interface {
+			Compute(key int, f func(old int, exists bool) (int, bool)) (int, bool)
+			Update(key int, f func(old int) int) bool
+			Get(key int) (int, bool)
+		}{
+			"ConcurrentQuickMap": NewConcurrentMap[int, int](nil),
+			"AtomicQuickMap":     NewAtomicMap[int, int](nil),
+		}
+		for name, m := range maps {
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 1000; i++ {
+						m.Compute(i%10, func(old int, exists bool) (int, bool) {
+							return old + 1, true
+						})
+					}
+				}()
+			}
+			wg.Wait()
+			for key := 0; key < 10; key++ {
+				if value, _ := m.Get(key); value != 800 {
+					t.Errorf("%s: Get(%d) = %d after concurrent increments, expected 800", name, key, value)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package quickmap
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// ConcurrentQuickMap is a hash table that is safe for concurrent use. Keys are
+// spread across independently locked shards by the high bits of their hash,
+// so operations on different shards never contend, and readers of the same
+// shard share its read lock.
+type ConcurrentQuickMap[K comparable, V any] struct {
+	shards []shard[K, V]
+	shift  uint
+	hasher Hasher[K]
+	seed   uint64
+}
+
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  *QuickMap[K, V]
+	// pad keeps neighbouring shard locks on separate cache lines
+	_ [64]byte
+}
+
+// ConcurrentStringMap is a ConcurrentQuickMap with string keys and untyped values, as returned by NewConcurrent
+type ConcurrentStringMap = ConcurrentQuickMap[string, interface{}]
+
+// NewConcurrent creates and returns a new ConcurrentQuickMap with string keys
+func NewConcurrent(opts ...Option) *ConcurrentStringMap {
+	return NewConcurrentMap[string, interface{}](nil, opts...)
+}
+
+// NewConcurrentMap creates and returns a new ConcurrentQuickMap that hashes
+// its keys with hasher. A nil hasher behaves as described for NewMap. Every
+// shard is a QuickMap built from opts; WithIncrementalResize is ignored, as
+// a resize only ever pauses the one shard that grows.
+func NewConcurrentMap[K comparable, V any](hasher Hasher[K], opts ...Option) *ConcurrentQuickMap[K, V] {
+	o := newOptions(opts)
+	o.resizeStep = 0
+	hasher = resolveHasher(hasher, o)
+
+	n := o.shards
+	if n < 1 {
+		n = runtime.GOMAXPROCS(0) * 4
+	}
+	n = 1 << bits.Len(uint(n-1))
+
+	m := &ConcurrentQuickMap[K, V]{
+		shards: make([]shard[K, V], n),
+		shift:  uint(64 - bits.TrailingZeros(uint(n))),
+		hasher: hasher,
+		seed:   o.seed,
+	}
+	for i := range m.shards {
+		m.shards[i].m = newMap[K, V](hasher, defaultInitialSize, o)
+	}
+	return m
+}
+
+// shardIndex returns the index of the shard that owns a key with hash h. With
+// a single shard the shift is 64, which Go defines to yield 0.
+func (m *ConcurrentQuickMap[K, V]) shardIndex(h uint64) int {
+	return int(h >> m.shift)
+}
+
+func (m *ConcurrentQuickMap[K, V]) shardFor(h uint64) *shard[K, V] {
+	return &m.shards[m.shardIndex(h)]
+}
+
+// Insert adds a new key-value pair to the map
+func (m *ConcurrentQuickMap[K, V]) Insert(key K, value V) {
+	h := m.hasher(key, m.seed)
+	s := m.shardFor(h)
+	s.mu.Lock()
+	before := s.m.state()
+	s.m.t.insert(key, h, value)
+	s.m.record(before, true)
+	s.m.checkInvariants()
+	s.mu.Unlock()
+}
+
+// Get retrieves a value by key
+func (m *ConcurrentQuickMap[K, V]) Get(key K) (V, bool) {
+	h := m.hasher(key, m.seed)
+	s := m.shardFor(h)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.m.t.get(key, h)
+	if s.m.metrics != nil {
+		s.m.recordGet(ok)
+	}
+	return value, ok
+}
+
+// Delete removes a key-value pair from the map
+func (m *ConcurrentQuickMap[K, V]) Delete(key K) {
+	h := m.hasher(key, m.seed)
+	s := m.shardFor(h)
+	s.mu.Lock()
+	before := s.m.state()
+	s.m.t.remove(key, h)
+	s.m.maybeShrink()
+	s.m.record(before, false)
+	s.m.checkInvariants()
+	s.mu.Unlock()
+}
+
+// Compute looks key up and calls f with its current value and whether it
+// exists, holding the key's shard lock throughout, so the whole operation is
+// atomic. If f returns true the key is stored with the value f returns,
+// otherwise it is deleted. Compute returns the key's value afterwards and
+// whether it is present. f must not use the map.
+func (m *ConcurrentQuickMap[K, V]) Compute(key K, f func(old V, exists bool) (V, bool)) (V, bool) {
+	h := m.hasher(key, m.seed)
+	s := m.shardFor(h)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := s.m.state()
+	value, ok := s.m.t.compute(key, h, f)
+	if !ok {
+		s.m.maybeShrink()
+	}
+	s.m.record(before, ok)
+	s.m.checkInvariants()
+	return value, ok
+}
+
+// GetOrInsert returns the existing value for key if present. Otherwise it
+// inserts value and returns it. loaded reports whether the key was present.
+func (m *ConcurrentQuickMap[K, V]) GetOrInsert(key K, value V) (actual V, loaded bool) {
+	actual, _ = m.Compute(key, getOrInsert(value, &loaded))
+	return actual, loaded
+}
+
+// LoadAndDelete deletes key and returns its previous value, if any. loaded
+// reports whether the key was present.
+func (m *ConcurrentQuickMap[K, V]) LoadAndDelete(key K) (previous V, loaded bool) {
+	m.Compute(key, loadAndDelete(&previous, &loaded))
+	return previous, loaded
+}
+
+// Swap stores value for key and returns the previous value, if any. loaded
+// reports whether the key was present.
+func (m *ConcurrentQuickMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	m.Compute(key, swap(value, &previous, &loaded))
+	return previous, loaded
+}
+
+// CompareAndSwap stores new for key if the key is present and its value
+// equals old, and reports whether it did. It panics if V is not comparable.
+func (m *ConcurrentQuickMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	m.Compute(key, compareAndSwap(old, new, &swapped))
+	return swapped
+}
+
+// Update replaces the value of key with the result of f applied to it, and
+// reports whether the key was present
+func (m *ConcurrentQuickMap[K, V]) Update(key K, f func(old V) V) (updated bool) {
+	m.Compute(key, update(f, &updated))
+	return updated
+}
+
+// Size returns the number of elements in the map. Under concurrent
+// modification the result is only a snapshot of each shard in turn.
+func (m *ConcurrentQuickMap[K, V]) Size() int {
+	size := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		size += s.m.Size()
+		s.mu.RUnlock()
+	}
+	return size
+}
+
+// ForEach iterates over all key-value pairs in the map, one shard at a time,
+// holding that shard's read lock while calling f. f must not modify the map.
+func (m *ConcurrentQuickMap[K, V]) ForEach(f func(key K, value V)) {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		// Walk the table directly: QuickMap.ForEach counts itself in walkers,
+		// which readers sharing the lock must not write
+		s.m.t.forEach(func(key K, value V) bool {
+			f(key, value)
+			return true
+		})
+		s.mu.RUnlock()
+	}
+}
+
+// InsertMany adds multiple key-value pairs to the map, taking each shard's
+// lock once
+func (m *ConcurrentQuickMap[K, V]) InsertMany(pairs map[K]V) {
+	type entry struct {
+		key   K
+		value V
+		h     uint64
+	}
+	byShard := make([][]entry, len(m.shards))
+	for k, v := range pairs {
+		h := m.hasher(k, m.seed)
+		i := m.shardIndex(h)
+		byShard[i] = append(byShard[i], entry{k, v, h})
+	}
+	for i, entries := range byShard {
+		if len(entries) == 0 {
+			continue
+		}
+		s := &m.shards[i]
+		s.mu.Lock()
+		before := s.m.state()
+		s.m.t.reserve(s.m.t.len() + len(entries))
+		for _, e := range entries {
+			s.m.t.insert(e.key, e.h, e.value)
+		}
+		s.m.record(before, false)
+		if s.m.metrics != nil {
+			s.m.metrics.inserts.Add(uint64(len(entries)))
+		}
+		s.m.checkInvariants()
+		s.mu.Unlock()
+	}
+}
+
+// DeleteMany removes multiple keys from the map, taking each shard's lock once
+func (m *ConcurrentQuickMap[K, V]) DeleteMany(keys []K) {
+	type entry struct {
+		key K
+		h   uint64
+	}
+	byShard := make([][]entry, len(m.shards))
+	for _, k := range keys {
+		h := m.hasher(k, m.seed)
+		i := m.shardIndex(h)
+		byShard[i] = append(byShard[i], entry{k, h})
+	}
+	for i, entries := range byShard {
+		if len(entries) == 0 {
+			continue
+		}
+		s := &m.shards[i]
+		s.mu.Lock()
+		before := s.m.state()
+		for _, e := range entries {
+			s.m.t.remove(e.key, e.h)
+		}
+		s.m.maybeShrink()
+		s.m.record(before, false)
+		s.m.checkInvariants()
+		s.mu.Unlock()
+	}
+}
+
+// Compact rebuilds every shard at the smallest capacity that holds its entries
+func (m *ConcurrentQuickMap[K, V]) Compact() {
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.Lock()
+		s.m.Compact()
+		s.mu.Unlock()
+	}
+}
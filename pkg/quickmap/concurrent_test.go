@@ -0,0 +1,160 @@
+package quickmap
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentQuickMap(t *testing.T) {
+	// Test the sequential API
+	t.Run("Insert, Get and Delete", func(t *testing.T) {
+		m := NewConcurrent(WithShards(3))
+		if len(m.shards) != 4 {
+			t.Errorf("WithShards(3) created %d shards, expected 4", len(m.shards))
+		}
+		m.Insert("key1", "value1")
+		if value, exists := m.Get("key1"); !exists || value != "value1" {
+			t.Errorf("Get(\"key1\") = %v, %t; expected \"value1\", true", value, exists)
+		}
+		m.Delete("key1")
+		if _, exists := m.Get("key1"); exists {
+			t.Errorf("Get(\"key1\") returned true after deletion, expected false")
+		}
+	})
+
+	// Test batch operations and ForEach across shards
+	t.Run("InsertMany and DeleteMany", func(t *testing.T) {
+		m := NewConcurrentMap[int, int](nil, WithShards(8))
+		pairs := make(map[int]int)
+		keys := make([]int, 0, 500)
+		for i := 0; i < 1000; i++ {
+			pairs[i] = i * 2
+			if i%2 == 0 {
+				keys = append(keys, i)
+			}
+		}
+		m.InsertMany(pairs)
+		m.DeleteMany(keys)
+		if m.Size() != 500 {
+			t.Errorf("Size() = %d, expected 500", m.Size())
+		}
+		sum := 0
+		m.ForEach(func(key, value int) {
+			if value != key*2 {
+				t.Errorf("ForEach visited %d = %d, expected %d", key, value, key*2)
+			}
+			sum += key
+		})
+		if sum != 250000 {
+			t.Errorf("Sum of keys visited by ForEach = %d, expected 250000", sum)
+		}
+	})
+
+	// Test a single shard
+	t.Run("One shard", func(t *testing.T) {
+		m := NewConcurrentMap[int, int](nil, WithShards(1))
+		for i := 0; i < 100; i++ {
+			m.Insert(i, i)
+		}
+		if m.Size() != 100 {
+			t.Errorf("Size() = %d, expected 100", m.Size())
+		}
+	})
+
+	// Test concurrent writers and readers; run with -race
+	t.Run("Parallel", func(t *testing.T) {
+		for _, backend := range backends {
+			m := NewConcurrentMap[string, int](nil, WithBackend(backend), WithShrinkFactor(0.1))
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					for i := 0; i < 2000; i++ {
+						key := strconv.Itoa(g*10000 + i)
+						m.Insert(key, i)
+						if value, exists := m.Get(key); !exists || value != i {
+							t.Errorf("Get(%q) = %d, %t; expected %d, true", key, value, exists, i)
+						}
+						if i%2 == 0 {
+							m.Delete(key)
+						}
+					}
+					m.DeleteMany([]string{strconv.Itoa(g*10000 + 1)})
+					m.ForEach(func(key string, value int) {})
+				}(g)
+			}
+			wg.Wait()
+			if m.Size() != 8*999 {
+				t.Errorf("%s: Size() = %d, expected %d", backend, m.Size(), 8*999)
+			}
+		}
+	})
+
+	// Test ForEach calls running at the same time as one another and as Get;
+	// run with -race
+	t.Run("Parallel ForEach", func(t *testing.T) {
+		m := NewConcurrentMap[int, int](nil, WithShards(2))
+		for i := 0; i < 1000; i++ {
+			m.Insert(i, i)
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					if g%2 == 0 {
+						count := 0
+						m.ForEach(func(key, value int) { count++ })
+						if count != 1000 {
+							t.Errorf("ForEach visited %d entries, expected 1000", count)
+						}
+					} else if value, ok := m.Get(i); !ok || value != i {
+						t.Errorf("Get(%d) = %d, %t; expected %d, true", i, value, ok, i)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkConcurrentQuickMap(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.Run("Sharded", func(b *testing.B) {
+		m := NewConcurrent()
+		for _, key := range keys {
+			m.Insert(key, 0)
+		}
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				m.Get(keys[i%len(keys)])
+				i++
+			}
+		})
+	})
+
+	b.Run("Global mutex", func(b *testing.B) {
+		var mu sync.RWMutex
+		m := New()
+		for _, key := range keys {
+			m.Insert(key, 0)
+		}
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				mu.RLock()
+				m.Get(keys[i%len(keys)])
+				mu.RUnlock()
+				i++
+			}
+		})
+	})
+}
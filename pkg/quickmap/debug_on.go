@@ -0,0 +1,6 @@
+//go:build quickmapdebug
+
+package quickmap
+
+// debug makes every mutation validate the map; see Validate
+const debug = true
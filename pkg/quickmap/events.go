@@ -0,0 +1,177 @@
+package quickmap
+
+import (
+	"slices"
+	"strconv"
+)
+
+// EventKind says what kind of change an Event describes
+type EventKind int
+
+const (
+	// Inserted reports that Key was added with Value
+	Inserted EventKind = iota + 1
+	// Updated reports that the value of Key changed from Old to Value
+	Updated
+	// Deleted reports that Key was removed; Value holds its last value
+	Deleted
+	// Cleared reports that every entry was removed at once
+	Cleared
+	// Resized reports that the table was rebuilt with Buckets buckets or slots
+	Resized
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Inserted:
+		return "inserted"
+	case Updated:
+		return "updated"
+	case Deleted:
+		return "deleted"
+	case Cleared:
+		return "cleared"
+	case Resized:
+		return "resized"
+	}
+	return "EventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Event describes one change to a map. Fields that do not apply to its Kind
+// hold zero values.
+type Event[K comparable, V any] struct {
+	Kind    EventKind
+	Key     K
+	Value   V
+	Old     V
+	Buckets int
+}
+
+// listeners holds the subscriptions to a map's events. subs is replaced, never
+// modified, so that a listener can cancel a subscription while being called.
+type listeners[K comparable, V any] struct {
+	subs   []subscription[K, V]
+	nextID int
+}
+
+type subscription[K comparable, V any] struct {
+	id int
+	f  func(Event[K, V])
+}
+
+// Subscribe calls f with an Event after every change to the map, in the
+// goroutine that makes the change, and returns a function that cancels the
+// subscription. f must not modify the map.
+//
+// Every key added, changed or removed gets its own event, including those of
+// InsertMany, DeleteMany and DeleteFunc. Overwriting a key is reported as
+// Updated even if the value is the same, except by GetOrInsert and a failed
+// CompareAndSwap, which change nothing and report nothing. Clear reports a
+// single Cleared event, and ReadFrom a Cleared event followed by an Inserted
+// event for each entry loaded. A change of capacity is reported as Resized
+// after the event for the change that caused it; for InsertMany, after the
+// events of all its pairs. Subscribing and cancelling
+// count as modifying the map.
+func (m *QuickMap[K, V]) Subscribe(f func(Event[K, V])) (cancel func()) {
+	if m.listeners == nil {
+		m.listeners = &listeners[K, V]{}
+	}
+	l := m.listeners
+	id := l.nextID
+	l.nextID++
+	l.subs = append(slices.Clip(l.subs), subscription[K, V]{id, f})
+	return func() {
+		l.subs = slices.DeleteFunc(slices.Clone(l.subs), func(s subscription[K, V]) bool {
+			return s.id == id
+		})
+		if len(l.subs) == 0 && m.listeners == l {
+			m.listeners = nil
+		}
+	}
+}
+
+// SubscribeChan is Subscribe with events sent to a channel with room for
+// buffer events. A change blocks while the channel is full, so the receiver
+// must keep up. Cancelling closes the channel; like any change, it must not
+// be made while another goroutine uses the map.
+func (m *QuickMap[K, V]) SubscribeChan(buffer int) (<-chan Event[K, V], func()) {
+	events := make(chan Event[K, V], buffer)
+	cancel := m.Subscribe(func(e Event[K, V]) {
+		events <- e
+	})
+	return events, func() {
+		cancel()
+		close(events)
+	}
+}
+
+func (m *QuickMap[K, V]) emit(e Event[K, V]) {
+	if m.listeners == nil {
+		return
+	}
+	for _, s := range m.listeners.subs {
+		s.f(e)
+	}
+}
+
+// notifyResize reports a change from capacity
+func (m *QuickMap[K, V]) notifyResize(capacity int) {
+	if c := m.t.capacity(); c != capacity {
+		m.emit(Event[K, V]{Kind: Resized, Buckets: c})
+	}
+}
+
+// notifyInsert reports storing value for key, which held old if existed
+func (m *QuickMap[K, V]) notifyInsert(key K, value, old V, existed bool, capacity int) {
+	if existed {
+		m.emit(Event[K, V]{Kind: Updated, Key: key, Value: value, Old: old})
+	} else {
+		m.emit(Event[K, V]{Kind: Inserted, Key: key, Value: value})
+	}
+	m.notifyResize(capacity)
+}
+
+// notifyDelete reports removing key, which held old if existed
+func (m *QuickMap[K, V]) notifyDelete(key K, old V, existed bool, capacity int) {
+	if existed {
+		m.emit(Event[K, V]{Kind: Deleted, Key: key, Value: old})
+	}
+	m.notifyResize(capacity)
+}
+
+// Clear removes every entry from the map and returns its table to the
+// default initial capacity
+func (m *QuickMap[K, V]) Clear() {
+	if m.t == nil {
+		return
+	}
+	before, capacity := m.state(), m.t.capacity()
+	m.t = m.emptyTable(int(defaultInitialSize * loadFactor))
+	m.walkers = 0
+	m.record(before, false)
+	m.checkInvariants()
+	if m.listeners != nil {
+		m.emit(Event[K, V]{Kind: Cleared})
+		m.notifyResize(capacity)
+	}
+}
+
+// removeFuncNotify is DeleteFunc for a map with listeners. The entries del
+// selects are collected during the pass and reported after it.
+func (m *QuickMap[K, V]) removeFuncNotify(del func(key K, value V) bool) {
+	capacity := m.t.capacity()
+	var removed []Event[K, V]
+	m.t.removeFunc(func(key K, value V) bool {
+		if del(key, value) {
+			removed = append(removed, Event[K, V]{Kind: Deleted, Key: key, Value: value})
+			return true
+		}
+		return false
+	})
+	m.maybeShrink()
+	m.checkInvariants()
+	for _, e := range removed {
+		m.emit(e)
+	}
+	m.notifyResize(capacity)
+}
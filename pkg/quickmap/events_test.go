@@ -0,0 +1,266 @@
+package quickmap
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// recorder collects the events of a map, leaving out Resized events unless
+// resizes is set
+type recorder struct {
+	events  []Event[int, int]
+	resizes bool
+}
+
+func (r *recorder) record(e Event[int, int]) {
+	if e.Kind != Resized || r.resizes {
+		r.events = append(r.events, e)
+	}
+}
+
+// take returns the events recorded so far, sorted by key, and forgets them
+func (r *recorder) take() []Event[int, int] {
+	events := r.events
+	r.events = nil
+	slices.SortStableFunc(events, func(a, b Event[int, int]) int { return a.Key - b.Key })
+	return events
+}
+
+func checkEvents(t *testing.T, op string, got []Event[int, int], expected ...Event[int, int]) {
+	t.Helper()
+	if !slices.Equal(got, expected) {
+		t.Errorf("%s sent %v, expected %v", op, got, expected)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	// Test the events of the basic operations on every backend
+	t.Run("Backends", func(t *testing.T) {
+		for _, backend := range []Backend{Chaining, Swiss, RobinHood} {
+			t.Run(backend.String(), func(t *testing.T) {
+				m := NewMap[int, int](nil, WithBackend(backend))
+				var r recorder
+				m.Subscribe(r.record)
+
+				m.Insert(1, 10)
+				checkEvents(t, "Insert(1, 10)", r.take(), Event[int, int]{Kind: Inserted, Key: 1, Value: 10})
+				m.Insert(1, 11)
+				checkEvents(t, "Insert(1, 11)", r.take(), Event[int, int]{Kind: Updated, Key: 1, Value: 11, Old: 10})
+				m.Delete(1)
+				checkEvents(t, "Delete(1)", r.take(), Event[int, int]{Kind: Deleted, Key: 1, Value: 11})
+				m.Delete(1)
+				checkEvents(t, "Delete(1) of an absent key", r.take())
+			})
+		}
+	})
+
+	// Test that InsertMany, DeleteMany and DeleteFunc send one event per key
+	t.Run("Batches", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		var r recorder
+		m.Subscribe(r.record)
+
+		m.Insert(1, 10)
+		r.take()
+		m.InsertMany(map[int]int{1: 11, 2: 20, 3: 30})
+		checkEvents(t, "InsertMany", r.take(),
+			Event[int, int]{Kind: Updated, Key: 1, Value: 11, Old: 10},
+			Event[int, int]{Kind: Inserted, Key: 2, Value: 20},
+			Event[int, int]{Kind: Inserted, Key: 3, Value: 30})
+		m.DeleteMany([]int{1, 2, 4})
+		checkEvents(t, "DeleteMany", r.take(),
+			Event[int, int]{Kind: Deleted, Key: 1, Value: 11},
+			Event[int, int]{Kind: Deleted, Key: 2, Value: 20})
+		m.InsertMany(map[int]int{4: 40, 5: 50})
+		r.take()
+		m.DeleteFunc(func(key, value int) bool { return key != 4 })
+		checkEvents(t, "DeleteFunc", r.take(),
+			Event[int, int]{Kind: Deleted, Key: 3, Value: 30},
+			Event[int, int]{Kind: Deleted, Key: 5, Value: 50})
+	})
+
+	// Test that the compound operations report only the changes they make
+	t.Run("Compound operations", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		var r recorder
+		m.Subscribe(r.record)
+
+		m.Compute(1, func(old int, exists bool) (int, bool) { return 10, true })
+		checkEvents(t, "Compute inserting", r.take(), Event[int, int]{Kind: Inserted, Key: 1, Value: 10})
+		m.Compute(1, func(old int, exists bool) (int, bool) { return old + 1, true })
+		checkEvents(t, "Compute updating", r.take(), Event[int, int]{Kind: Updated, Key: 1, Value: 11, Old: 10})
+		m.Compute(2, func(old int, exists bool) (int, bool) { return 0, false })
+		checkEvents(t, "Compute deleting an absent key", r.take())
+		m.GetOrInsert(1, 12)
+		checkEvents(t, "GetOrInsert of a present key", r.take())
+		m.GetOrInsert(2, 20)
+		checkEvents(t, "GetOrInsert of an absent key", r.take(), Event[int, int]{Kind: Inserted, Key: 2, Value: 20})
+		m.CompareAndSwap(1, 10, 12)
+		checkEvents(t, "CompareAndSwap failing", r.take())
+		m.CompareAndSwap(1, 11, 12)
+		checkEvents(t, "CompareAndSwap succeeding", r.take(), Event[int, int]{Kind: Updated, Key: 1, Value: 12, Old: 11})
+		m.Swap(2, 21)
+		checkEvents(t, "Swap", r.take(), Event[int, int]{Kind: Updated, Key: 2, Value: 21, Old: 20})
+		m.LoadAndDelete(2)
+		checkEvents(t, "LoadAndDelete", r.take(), Event[int, int]{Kind: Deleted, Key: 2, Value: 21})
+	})
+
+	// Test that growing, compacting and clearing report the new capacity
+	t.Run("Resized", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 1)
+		r := recorder{resizes: true}
+		m.Subscribe(r.record)
+
+		for i := 0; i < 100; i++ {
+			m.Insert(i, i)
+		}
+		var buckets int
+		for i, e := range r.events {
+			if e.Kind != Resized {
+				continue
+			}
+			if i == 0 || r.events[i-1].Kind != Inserted {
+				t.Errorf("Resized event %d follows %v, expected an Inserted event", i, r.events[i-1])
+			}
+			buckets = e.Buckets
+		}
+		if buckets == 0 || buckets != m.t.capacity() {
+			t.Errorf("last Resized event has %d buckets, expected %d", buckets, m.t.capacity())
+		}
+
+		r.events = nil
+		pairs := make(map[int]int)
+		for i := 100; i < 1000; i++ {
+			pairs[i] = i
+		}
+		m.InsertMany(pairs)
+		buckets = 0
+		for i, e := range r.events {
+			if e.Kind != Resized {
+				continue
+			}
+			if i == 0 || r.events[i-1].Kind != Inserted {
+				t.Errorf("InsertMany sent Resized as event %d, expected it after an Inserted event", i)
+			}
+			buckets = e.Buckets
+		}
+		if buckets != m.t.capacity() {
+			t.Errorf("last Resized event of InsertMany has %d buckets, expected %d", buckets, m.t.capacity())
+		}
+
+		r.events = nil
+		m.DeleteFunc(func(key, value int) bool { return key > 0 })
+		m.Compact()
+		if e := r.events[len(r.events)-1]; e.Kind != Resized || e.Buckets != m.t.capacity() {
+			t.Errorf("last event after Compact() = %v, expected Resized with %d buckets", e, m.t.capacity())
+		}
+
+		r.events = nil
+		m.Clear()
+		if len(r.events) == 0 || r.events[0].Kind != Cleared {
+			t.Errorf("Clear() sent %v, expected Cleared first", r.events)
+		}
+		if m.Size() != 0 {
+			t.Errorf("Size() = %d after Clear(), expected 0", m.Size())
+		}
+		checkOperations(t, m)
+	})
+
+	// Test that ReadFrom reports replacing the contents
+	t.Run("ReadFrom", func(t *testing.T) {
+		src := NewMap[int, int](nil)
+		src.InsertMany(map[int]int{1: 10, 2: 20})
+		var buf bytes.Buffer
+		if _, err := src.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo() returned %v", err)
+		}
+
+		m := NewMap[int, int](nil)
+		m.Insert(3, 30)
+		var r recorder
+		m.Subscribe(r.record)
+		if _, err := m.ReadFrom(&buf); err != nil {
+			t.Fatalf("ReadFrom() returned %v", err)
+		}
+		checkEvents(t, "ReadFrom", r.take(),
+			Event[int, int]{Kind: Cleared},
+			Event[int, int]{Kind: Inserted, Key: 1, Value: 10},
+			Event[int, int]{Kind: Inserted, Key: 2, Value: 20})
+	})
+
+	// Test that cancelling stops events, including from inside a listener
+	t.Run("Cancel", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		var first, second recorder
+		var cancelFirst func()
+		cancelFirst = m.Subscribe(func(e Event[int, int]) {
+			first.record(e)
+			cancelFirst()
+		})
+		cancelSecond := m.Subscribe(second.record)
+
+		m.Insert(1, 10)
+		m.Insert(2, 20)
+		cancelSecond()
+		m.Insert(3, 30)
+		if len(first.events) != 1 || len(second.events) != 2 {
+			t.Errorf("listeners got %d and %d events, expected 1 and 2", len(first.events), len(second.events))
+		}
+		if m.listeners != nil {
+			t.Errorf("listeners remain after cancelling every subscription")
+		}
+	})
+
+	// Test delivery through a channel
+	t.Run("Channel", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		events, cancel := m.SubscribeChan(4)
+		m.Insert(1, 10)
+		m.Delete(1)
+		cancel()
+		m.Insert(2, 20)
+
+		var got []Event[int, int]
+		for e := range events {
+			got = append(got, e)
+		}
+		checkEvents(t, "Insert and Delete", got,
+			Event[int, int]{Kind: Inserted, Key: 1, Value: 10},
+			Event[int, int]{Kind: Deleted, Key: 1, Value: 10})
+	})
+
+	// Test events from a LinkedQuickMap, and that Clear keeps it usable
+	t.Run("Linked", func(t *testing.T) {
+		m := NewLinkedMap[int, int](nil)
+		var r recorder
+		m.Subscribe(r.record)
+		m.Insert(1, 10)
+		m.Insert(2, 20)
+		m.PopFirst()
+		checkEvents(t, "Insert and PopFirst", r.take(),
+			Event[int, int]{Kind: Inserted, Key: 1, Value: 10},
+			Event[int, int]{Kind: Deleted, Key: 1, Value: 10},
+			Event[int, int]{Kind: Inserted, Key: 2, Value: 20})
+
+		m.Clear()
+		m.Insert(3, 30)
+		if key, _, ok := m.First(); !ok || key != 3 {
+			t.Errorf("First() = %d, %t after Clear() and Insert(3, 30), expected 3, true", key, ok)
+		}
+	})
+
+	// Test that Clear on a map with metrics updates them
+	t.Run("Clear metrics", func(t *testing.T) {
+		var metrics Metrics
+		m := NewMap[int, int](nil, WithMetrics(&metrics))
+		for i := 0; i < 100; i++ {
+			m.Insert(i, i)
+		}
+		m.Clear()
+		s := metrics.Snapshot()
+		if s.Size != 0 || s.Deletes != 100 || s.Buckets != int64(m.Stats().Buckets) {
+			t.Errorf("Snapshot() = %+v after Clear(), expected size 0, 100 deletes and %d buckets", s, m.Stats().Buckets)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package quickmap
+
+import (
+	"testing"
+
+	"github.com/marpit19/goquickmap/internal/maptest"
+)
+
+// FuzzQuickMap checks QuickMap against the built-in map. The first byte of
+// the input picks the configuration and the rest is decoded by maptest.
+func FuzzQuickMap(f *testing.F) {
+	f.Add([]byte{0x00, 0, 1, 10, 1, 1, 2, 1, 1, 1})
+	f.Add([]byte{0x01, 0, 1, 1, 0, 4, 2, 0, 7, 0, 4, 9, 0, 1, 3, 5})
+	f.Add([]byte{0x12, 3, 5, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 5, 4, 3, 1, 2, 3, 5})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 {
+			return
+		}
+		if err := maptest.Check(data[1:], fuzzMap(data[0])); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// fuzzMap returns an empty map configured by config. Bit 0 selects a hasher
+// that sends every key to one of three hashes, bits 1 to 3 the table and bit
+// 4 shrinking. Maps start at the smallest capacity, so that short inputs
+// still resize them.
+func fuzzMap(config byte) maptest.Map {
+	var hasher Hasher[int]
+	if config&1 != 0 {
+		hasher = func(key int, _ uint64) uint64 { return uint64(key % 3) }
+	}
+	var opts []Option
+	if config&0x10 != 0 {
+		opts = append(opts, WithShrinkFactor(0.25))
+	}
+	switch config >> 1 & 7 % 5 {
+	case 1:
+		opts = append(opts, WithBackend(Swiss))
+	case 2:
+		opts = append(opts, WithBackend(RobinHood))
+	case 3:
+		opts = append(opts, WithIncrementalResize(1))
+	case 4:
+		return NewLinkedMapWithCapacity[int, int](hasher, 1, opts...)
+	}
+	return NewMapWithCapacity[int, int](hasher, 1, opts...)
+}
@@ -0,0 +1,130 @@
+package quickmap
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/marpit19/goquickmap/internal/hash"
+)
+
+// Hasher computes a hash value for a key of type K. Every QuickMap draws a
+// random seed at construction and passes it on each call; a Hasher should mix
+// the seed into the result so that colliding keys cannot be chosen in advance.
+type Hasher[K comparable] func(key K, seed uint64) uint64
+
+// Integer is the set of integer types accepted by IntegerHasher
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// StringHasher hashes string keys with SipHash-2-4 keyed by the seed
+func StringHasher[K ~string](key K, seed uint64) uint64 {
+	return hash.String(string(key), seed)
+}
+
+// FNV1Hasher hashes string keys with FNV-1, folding the seed into the offset
+// basis. It is the fastest option for short keys but, unlike StringHasher,
+// does not resist crafted collisions.
+func FNV1Hasher[K ~string](key K, seed uint64) uint64 {
+	return hash.FNV(string(key), seed)
+}
+
+// WyHasher hashes string keys with a wyhash-style 64-bit hash, which is
+// faster than StringHasher on long keys
+func WyHasher[K ~string](key K, seed uint64) uint64 {
+	return hash.Wy(string(key), seed)
+}
+
+// MapHasher hashes string keys with hash/maphash. Its output depends on a
+// per-process seed, so layouts are not reproducible across runs even with WithSeed.
+func MapHasher[K ~string](key K, seed uint64) uint64 {
+	return hash.Map(string(key), seed)
+}
+
+// IntegerHasher hashes integer keys with SipHash-2-4 keyed by the seed
+func IntegerHasher[K Integer](key K, seed uint64) uint64 {
+	return hash.Uint64(uint64(key), seed)
+}
+
+// DefaultHasher returns the built-in Hasher for K. Strings and the predeclared
+// integer types are supported; for any other key type it panics, and a Hasher
+// has to be passed to NewMap explicitly.
+func DefaultHasher[K comparable]() Hasher[K] {
+	var zero K
+	var h interface{}
+	switch any(zero).(type) {
+	case string:
+		h = Hasher[string](StringHasher[string])
+	case int:
+		h = Hasher[int](IntegerHasher[int])
+	case int8:
+		h = Hasher[int8](IntegerHasher[int8])
+	case int16:
+		h = Hasher[int16](IntegerHasher[int16])
+	case int32:
+		h = Hasher[int32](IntegerHasher[int32])
+	case int64:
+		h = Hasher[int64](IntegerHasher[int64])
+	case uint:
+		h = Hasher[uint](IntegerHasher[uint])
+	case uint8:
+		h = Hasher[uint8](IntegerHasher[uint8])
+	case uint16:
+		h = Hasher[uint16](IntegerHasher[uint16])
+	case uint32:
+		h = Hasher[uint32](IntegerHasher[uint32])
+	case uint64:
+		h = Hasher[uint64](IntegerHasher[uint64])
+	case uintptr:
+		h = Hasher[uintptr](IntegerHasher[uintptr])
+	default:
+		panic(fmt.Sprintf("quickmap: no default hasher for key type %T", zero))
+	}
+	return h.(Hasher[K])
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Hasher[string]{
+		"siphash": StringHasher[string],
+		"fnv1":    FNV1Hasher[string],
+		"wyhash":  WyHasher[string],
+		"maphash": MapHasher[string],
+	}
+)
+
+// RegisterHasher makes a string Hasher available to WithHashFunc under the
+// given name. It panics if the name is already registered or h is nil.
+func RegisterHasher(name string, h Hasher[string]) {
+	if h == nil {
+		panic("quickmap: RegisterHasher hasher is nil")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("quickmap: RegisterHasher called twice for " + name)
+	}
+	registry[name] = h
+}
+
+// LookupHasher returns the string Hasher registered under name
+func LookupHasher(name string) (Hasher[string], bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	h, ok := registry[name]
+	return h, ok
+}
+
+// HasherNames returns the sorted names of all registered string Hashers
+func HasherNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
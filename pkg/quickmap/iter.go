@@ -0,0 +1,69 @@
+package quickmap
+
+import "iter"
+
+// All, Keys and Values return iterators for use with range. Entries are
+// visited in no particular order, and a loop can stop early with break.
+//
+// The map may be modified while it is being iterated, by these iterators or
+// by ForEach. An entry that is deleted before the loop reaches it is not
+// visited, and an entry whose value is changed is visited with its new value.
+// An entry inserted during the loop may or may not be visited. Every other
+// entry is visited exactly once.
+//
+// Overwriting the value of an existing key during iteration is cheap, and so
+// is deleting with the Chaining backend or from a LinkedQuickMap, which
+// unlink the entry in place. Any other change to the table's layout during an
+// iteration, such as inserting a new key, deleting with the Swiss or
+// RobinHood backends, or reordering a LinkedQuickMap, first copies the whole
+// table, taking time and memory in proportion to its size. The loop keeps
+// walking the original while the copy changes, and later changes in the same
+// loop do not copy it again. Once the table has been copied or changed in
+// place, each remaining entry is checked against the map.
+
+// All returns an iterator over the key-value pairs in the map
+func (m *QuickMap[K, V]) All() iter.Seq2[K, V] {
+	return m.iterate
+}
+
+// Keys returns an iterator over the keys in the map
+func (m *QuickMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.iterate(func(key K, value V) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Values returns an iterator over the values in the map
+func (m *QuickMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.iterate(func(key K, value V) bool {
+			return yield(value)
+		})
+	}
+}
+
+// iterate walks the table that is current when it starts. Once yield has
+// caused the map to detach from that table, or removed entries from it in
+// place, each entry is looked up in the live table instead, which skips
+// deleted keys and picks up new values.
+func (m *QuickMap[K, V]) iterate(yield func(key K, value V) bool) {
+	t, unlinks := m.t, m.unlinks
+	m.walkers++
+	defer func() {
+		if m.t == t {
+			m.walkers--
+		}
+	}()
+
+	t.forEach(func(key K, value V) bool {
+		if m.t != t || m.unlinks != unlinks {
+			var ok bool
+			if value, ok = m.t.get(key, m.hash(key)); !ok {
+				return true
+			}
+		}
+		return yield(key, value)
+	})
+}
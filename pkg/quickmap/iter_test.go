@@ -0,0 +1,301 @@
+package quickmap
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	configs := map[string][]Option{
+		"incremental": {WithIncrementalResize(1)},
+	}
+	for _, backend := range backends {
+		configs[backend.String()] = []Option{WithBackend(backend)}
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			m := NewMapWithCapacity[string, int](nil, 1, opts...)
+			expected := make(map[string]int)
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i)
+				m.Insert(key, i)
+				expected[key] = i
+			}
+
+			// All visits every pair exactly once, including mid-resize
+			if all := maps.Collect(m.All()); !maps.Equal(all, expected) {
+				t.Errorf("All() collected %d pairs, expected %d matching pairs", len(all), len(expected))
+			}
+			keys := slices.Collect(m.Keys())
+			if len(keys) != 1000 {
+				t.Errorf("len(Keys()) = %d, expected 1000", len(keys))
+			}
+			sum := 0
+			for value := range m.Values() {
+				sum += value
+			}
+			if sum != 999*1000/2 {
+				t.Errorf("Sum of Values() = %d, expected %d", sum, 999*1000/2)
+			}
+
+			// break stops the iteration
+			visited := 0
+			for key, value := range m.All() {
+				if expected[key] != value {
+					t.Errorf("All() yielded %q = %d, expected %d", key, value, expected[key])
+				}
+				visited++
+				if visited == 10 {
+					break
+				}
+			}
+			if visited != 10 {
+				t.Errorf("All() visited %d pairs before break, expected 10", visited)
+			}
+			visited = 0
+			for range m.Keys() {
+				visited++
+				break
+			}
+			for range m.Values() {
+				visited++
+				break
+			}
+			if visited != 2 {
+				t.Errorf("Keys() and Values() visited %d entries before break, expected 2", visited)
+			}
+		})
+	}
+}
+
+func TestMutationDuringIteration(t *testing.T) {
+	configs := map[string][]Option{
+		"incremental": {WithIncrementalResize(1)},
+		"shrinking":   {WithShrinkFactor(0.2)},
+	}
+	for _, backend := range backends {
+		configs[backend.String()] = []Option{WithBackend(backend)}
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			newMap := func() *QuickMap[int, int] {
+				m := NewMapWithCapacity[int, int](nil, 1, opts...)
+				for i := 0; i < 1000; i++ {
+					m.Insert(i, i)
+				}
+				return m
+			}
+
+			// Deleted entries are not visited and the rest are visited once
+			m := newMap()
+			seen := make(map[int]int)
+			for key := range m.Keys() {
+				seen[key]++
+				m.Delete(key ^ 1)
+			}
+			for key, count := range seen {
+				if count != 1 {
+					t.Errorf("Key %d visited %d times, expected once", key, count)
+				}
+				if seen[key^1] != 0 {
+					t.Errorf("Keys %d and %d both visited, expected one to be deleted first", key, key^1)
+				}
+			}
+			if len(seen) != 500 || m.Size() != 500 {
+				t.Errorf("Visited %d keys leaving Size() = %d, expected 500 and 500", len(seen), m.Size())
+			}
+
+			// Inserts, which grow the table, do not disturb the iteration
+			m = newMap()
+			seen = make(map[int]int)
+			m.ForEach(func(key, value int) {
+				seen[key]++
+				m.Insert(key+100000, value)
+			})
+			for i := 0; i < 1000; i++ {
+				if seen[i] != 1 {
+					t.Errorf("Key %d visited %d times, expected once", i, seen[i])
+				}
+			}
+			if m.Size() != 2000 {
+				t.Errorf("Size() = %d after inserting during ForEach, expected 2000", m.Size())
+			}
+
+			// Overwritten values are seen, without copying the table
+			m = newMap()
+			before := m.t
+			for key, value := range m.All() {
+				if value != key && value != -key {
+					t.Errorf("All() yielded %d = %d, expected %d or %d", key, value, key, -key)
+				}
+				m.Insert(999-key, -(999 - key))
+				m.Update(key, func(old int) int { return old })
+			}
+			if m.t != before {
+				t.Errorf("Overwriting values during iteration copied the table")
+			}
+
+			// Nested iterations each see a consistent view
+			m = newMap()
+			outer := 0
+			for key := range m.Keys() {
+				outer++
+				if key%100 == 0 {
+					inner := 0
+					for range m.Keys() {
+						inner++
+						m.Delete(key + 1)
+					}
+					if inner != m.Size() && inner != m.Size()+1 {
+						t.Errorf("Inner iteration visited %d keys, Size() = %d", inner, m.Size())
+					}
+				}
+			}
+			if m.Size() != 990 || outer > 1000 {
+				t.Errorf("Size() = %d after nested iteration visited %d keys, expected 990", m.Size(), outer)
+			}
+			if m.walkers != 0 {
+				t.Errorf("walkers = %d after every iteration finished, expected 0", m.walkers)
+			}
+		})
+	}
+}
+
+func TestDeleteInPlaceDuringIteration(t *testing.T) {
+	// Keys fall into four long chains, so deletes often unlink the node being
+	// visited and those after it
+	collide := func(key int, seed uint64) uint64 { return uint64(key % 4) }
+	maps := map[string]func() *QuickMap[int, int]{
+		"chaining": func() *QuickMap[int, int] { return NewMapWithCapacity[int, int](collide, 64) },
+		"linked": func() *QuickMap[int, int] {
+			return &NewLinkedMapWithCapacity[int, int](collide, 64).QuickMap
+		},
+	}
+	for name, newMap := range maps {
+		t.Run(name, func(t *testing.T) {
+			m := newMap()
+			for i := 0; i < 40; i++ {
+				m.Insert(i, i)
+			}
+			before := m.t
+			seen := make(map[int]int)
+			for key, value := range m.All() {
+				seen[key]++
+				if value != key {
+					t.Errorf("All() yielded %d = %d, expected %d", key, value, key)
+				}
+				// Delete this key and the next two in its chain
+				m.Delete(key)
+				m.DeleteMany([]int{key + 4, key + 8})
+			}
+			if m.t != before {
+				t.Errorf("Deleting during iteration copied the table")
+			}
+			for key, count := range seen {
+				if count != 1 {
+					t.Errorf("Key %d visited %d times, expected once", key, count)
+				}
+				if seen[key+4] != 0 || seen[key+8] != 0 {
+					t.Errorf("Key %d visited with %d or %d, expected them deleted first", key, key+4, key+8)
+				}
+			}
+			if m.Size() != 0 || len(seen) == 0 {
+				t.Errorf("Visited %d keys leaving Size() = %d, expected some and 0", len(seen), m.Size())
+			}
+			if err := m.Validate(); err != nil {
+				t.Errorf("Validate() = %v after deleting during iteration", err)
+			}
+		})
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	configs := map[string][]Option{
+		"incremental": {WithIncrementalResize(1)},
+	}
+	for _, backend := range backends {
+		configs[backend.String()] = []Option{WithBackend(backend)}
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			m := NewMapWithCapacity[int, int](nil, 1, opts...)
+			for i := 0; i < 1000; i++ {
+				m.Insert(i, i*i)
+			}
+			calls := make(map[int]int)
+			m.DeleteFunc(func(key, value int) bool {
+				calls[key]++
+				if value != key*key {
+					t.Errorf("DeleteFunc called with %d = %d, expected %d", key, value, key*key)
+				}
+				return key%3 != 0
+			})
+			for i := 0; i < 1000; i++ {
+				if calls[i] != 1 {
+					t.Errorf("DeleteFunc called %d times for key %d, expected once", calls[i], i)
+				}
+				if _, exists := m.Get(i); exists != (i%3 == 0) {
+					t.Errorf("Get(%d) = %t after DeleteFunc, expected %t", i, exists, i%3 == 0)
+				}
+			}
+			if m.Size() != 334 {
+				t.Errorf("Size() = %d after DeleteFunc, expected 334", m.Size())
+			}
+		})
+	}
+
+	// Deletions that wrap around the end of a Robin Hood table
+	t.Run("robinhood/wrap", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](func(key int, seed uint64) uint64 {
+			return uint64(key%4) + 124
+		}, 64, WithBackend(RobinHood))
+		for i := 0; i < 40; i++ {
+			m.Insert(i, i)
+		}
+		if rt := m.t.(*robinTable[int, int]); rt.hashes[0] == 0 {
+			t.Fatalf("Entries do not wrap around a table of capacity %d", len(rt.hashes))
+		}
+		calls := 0
+		m.DeleteFunc(func(key, value int) bool {
+			calls++
+			return key%2 == 0
+		})
+		checkRobinInvariant(t, m.t.(*robinTable[int, int]))
+		if calls != 40 || m.Size() != 20 {
+			t.Errorf("DeleteFunc made %d calls leaving Size() = %d, expected 40 and 20", calls, m.Size())
+		}
+		for i := 1; i < 40; i += 2 {
+			if _, exists := m.Get(i); !exists {
+				t.Errorf("Get(%d) returned false after DeleteFunc, expected true", i)
+			}
+		}
+	})
+}
+
+// BenchmarkDeleteDuringIteration deletes every other key from inside ForEach.
+// The Chaining backend unlinks them in place; the others copy the table on
+// the first delete.
+func BenchmarkDeleteDuringIteration(b *testing.B) {
+	for _, backend := range backends {
+		b.Run(backend.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				m := NewMap[int, int](nil, WithBackend(backend))
+				for key := 0; key < 10000; key++ {
+					m.Insert(key, key)
+				}
+				b.StartTimer()
+				m.ForEach(func(key, value int) {
+					if key%2 == 0 {
+						m.Delete(key)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package quickmap
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/marpit19/goquickmap/internal/jsonenc"
+)
+
+// MarshalJSON encodes the map as a JSON object. Keys must have a string or
+// integer type or implement encoding.TextMarshaler, as for Go maps in
+// encoding/json. A map whose value type is struct{}, like the one behind a
+// QuickSet, encodes as a JSON array of its keys instead. Entries are written
+// in iteration order unless the map was created with WithSortedJSON.
+func (m *QuickMap[K, V]) MarshalJSON() ([]byte, error) {
+	if isSet[V]() {
+		if m.t == nil {
+			return []byte("[]"), nil
+		}
+		return jsonenc.EncodeArray(m.Keys(), m.sortedJSON)
+	}
+	if m.t == nil {
+		return []byte("{}"), nil
+	}
+	return jsonenc.EncodeObject(m.All(), m.sortedJSON)
+}
+
+// UnmarshalJSON decodes a JSON object, or an array for a map of struct{}
+// values, into the map. Entries are added to those already present, as
+// encoding/json does for Go maps. A zero QuickMap is first initialised as if
+// by NewMap with no hasher and no options.
+func (m *QuickMap[K, V]) UnmarshalJSON(data []byte) error {
+	return m.DecodeJSON(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeJSON reads the next JSON value from dec into the map, as described
+// for UnmarshalJSON. Each value is decoded straight into the table as its
+// member is read, so a large object can be streamed from an io.Reader
+// without building an intermediate Go map:
+//
+//	err := m.DecodeJSON(json.NewDecoder(file))
+func (m *QuickMap[K, V]) DecodeJSON(dec *json.Decoder) error {
+	if m.t == nil {
+		o := newOptions(nil)
+		m.init(resolveHasher[K](nil, o), defaultInitialSize, o)
+	}
+	if isSet[V]() {
+		var present V
+		return jsonenc.DecodeArray(dec, func(key K) {
+			m.Insert(key, present)
+		})
+	}
+	return jsonenc.DecodeObject(dec, m.Insert)
+}
+
+// isSet reports whether V is struct{}, the value type QuickSet uses
+func isSet[V any]() bool {
+	var zero V
+	_, ok := any(zero).(struct{})
+	return ok
+}
@@ -0,0 +1,129 @@
+package quickmap
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	// Test a round trip through a struct field
+	t.Run("Round trip", func(t *testing.T) {
+		type document struct {
+			Scores *QuickMap[string, int] `json:"scores"`
+		}
+		in := document{Scores: NewMap[string, int](nil)}
+		for i := 0; i < 100; i++ {
+			in.Scores.Insert(strconv.Itoa(i), i)
+		}
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal returned %v, expected nil", err)
+		}
+		var out document
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal returned %v, expected nil", err)
+		}
+		if out.Scores.Size() != 100 {
+			t.Errorf("Size() = %d after round trip, expected 100", out.Scores.Size())
+		}
+		if value, exists := out.Scores.Get("42"); !exists || value != 42 {
+			t.Errorf("Get(\"42\") = %d, %t; expected 42, true", value, exists)
+		}
+	})
+
+	// Test sorted output with integer keys
+	t.Run("Sorted", func(t *testing.T) {
+		m := NewMap[int, string](nil, WithSortedJSON())
+		for _, key := range []int{3, 1, 2} {
+			m.Insert(key, strconv.Itoa(key*10))
+		}
+		data, err := json.Marshal(m)
+		if err != nil || string(data) != `{"1":"10","2":"20","3":"30"}` {
+			t.Errorf("Marshal = %s, %v; expected {\"1\":\"10\",\"2\":\"20\",\"3\":\"30\"}, nil", data, err)
+		}
+	})
+
+	// Test that insertion order is kept by a LinkedQuickMap
+	t.Run("Linked", func(t *testing.T) {
+		m := NewLinkedMap[string, int](nil)
+		for _, key := range []string{"c", "a", "b"} {
+			m.Insert(key, len(key))
+		}
+		data, err := json.Marshal(m)
+		if err != nil || string(data) != `{"c":1,"a":1,"b":1}` {
+			t.Errorf("Marshal = %s, %v; expected {\"c\":1,\"a\":1,\"b\":1}, nil", data, err)
+		}
+	})
+
+	// Test that struct{} values encode as an array of keys
+	t.Run("Set values", func(t *testing.T) {
+		m := NewMap[int, struct{}](nil, WithSortedJSON())
+		for _, key := range []int{10, 9, 100} {
+			m.Insert(key, struct{}{})
+		}
+		data, err := json.Marshal(m)
+		if err != nil || string(data) != `[9,10,100]` {
+			t.Errorf("Marshal = %s, %v; expected [9,10,100], nil", data, err)
+		}
+		decoded := NewMap[int, struct{}](nil)
+		if err := json.Unmarshal(data, decoded); err != nil || decoded.Size() != 3 {
+			t.Errorf("Unmarshal returned %v with Size() = %d, expected nil and 3", err, decoded.Size())
+		}
+	})
+
+	// Test that decoding merges into existing entries and accepts null
+	t.Run("Merge and null", func(t *testing.T) {
+		m := NewMap[string, int](nil)
+		m.Insert("a", 1)
+		if err := json.Unmarshal([]byte(`{"a":2,"b":3}`), m); err != nil {
+			t.Fatalf("Unmarshal returned %v, expected nil", err)
+		}
+		if err := json.Unmarshal([]byte(`null`), m); err != nil {
+			t.Fatalf("Unmarshal(null) returned %v, expected nil", err)
+		}
+		if value, _ := m.Get("a"); value != 2 || len(slices.Collect(m.Keys())) != 2 {
+			t.Errorf("Get(\"a\") = %d with Keys() = %v, expected 2 and [a b]", value, slices.Collect(m.Keys()))
+		}
+		if m.Size() != 2 {
+			t.Errorf("Size() = %d, expected 2", m.Size())
+		}
+	})
+
+	// Test streaming a large object from a reader
+	t.Run("DecodeJSON", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("{")
+		for i := 0; i < 10000; i++ {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(`"` + strconv.Itoa(i) + `":` + strconv.Itoa(i))
+		}
+		b.WriteString("}")
+		var m QuickMap[string, int]
+		if err := m.DecodeJSON(json.NewDecoder(strings.NewReader(b.String()))); err != nil {
+			t.Fatalf("DecodeJSON returned %v, expected nil", err)
+		}
+		if m.Size() != 10000 {
+			t.Errorf("Size() = %d after DecodeJSON, expected 10000", m.Size())
+		}
+	})
+
+	// Test errors for bad input and unsupported keys
+	t.Run("Errors", func(t *testing.T) {
+		m := NewMap[int, int](nil)
+		for _, input := range []string{`[1,2]`, `{"x":1}`, `{"1":"one"}`} {
+			if err := json.Unmarshal([]byte(input), m); err == nil {
+				t.Errorf("Unmarshal(%s) returned nil, expected an error", input)
+			}
+		}
+		f := NewMap[float64, int](func(key float64, seed uint64) uint64 { return uint64(key) ^ seed })
+		f.Insert(1.5, 1)
+		if _, err := json.Marshal(f); err == nil {
+			t.Errorf("Marshal with float64 keys returned nil, expected an error")
+		}
+	})
+}
@@ -0,0 +1,357 @@
+package quickmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// LinkedQuickMap is a QuickMap that remembers the order in which keys were
+// inserted. ForEach, All, Keys and Values visit entries in that order, which
+// is stable across resizes and runs. Overwriting the value of an existing key
+// keeps its position; MoveToFront and MoveToBack change it explicitly.
+// InsertMany takes a map, so the keys it adds are appended in no particular
+// order among themselves.
+//
+// A LinkedQuickMap always uses chained buckets, with a doubly linked list
+// threaded through the same nodes, so WithBackend and WithIncrementalResize
+// are ignored. Every QuickMap method is available through the embedded map.
+type LinkedQuickMap[K comparable, V any] struct {
+	QuickMap[K, V]
+}
+
+// LinkedStringMap is a LinkedQuickMap with string keys and untyped values, as returned by NewLinked
+type LinkedStringMap = LinkedQuickMap[string, interface{}]
+
+// NewLinked creates and returns a new LinkedQuickMap with string keys
+func NewLinked(opts ...Option) *LinkedStringMap {
+	return NewLinkedMap[string, interface{}](nil, opts...)
+}
+
+// NewLinkedMap creates and returns a new LinkedQuickMap that hashes its keys
+// with hasher. A nil hasher behaves as described for NewMap.
+func NewLinkedMap[K comparable, V any](hasher Hasher[K], opts ...Option) *LinkedQuickMap[K, V] {
+	return NewLinkedMapWithCapacity[K, V](hasher, defaultInitialSize, opts...)
+}
+
+// NewLinkedMapWithCapacity creates and returns a new LinkedQuickMap with the specified hasher and initial capacity
+func NewLinkedMapWithCapacity[K comparable, V any](hasher Hasher[K], initialCapacity int, opts ...Option) *LinkedQuickMap[K, V] {
+	if initialCapacity < 1 {
+		initialCapacity = defaultInitialSize
+	}
+	o := newOptions(opts)
+	m := &LinkedQuickMap[K, V]{}
+	m.configure(resolveHasher(hasher, o), o)
+	m.t = newLinkedTable[K, V](initialCapacity, m.hash)
+	m.trackTable()
+	return m
+}
+
+func (m *LinkedQuickMap[K, V]) linked() *linkedTable[K, V] {
+	return m.t.(*linkedTable[K, V])
+}
+
+// initZero initialises a zero LinkedQuickMap as if by NewLinkedMap with no
+// hasher and no options, so that decoding into it keeps the order
+func (m *LinkedQuickMap[K, V]) initZero() {
+	if m.t == nil {
+		*m = *NewLinkedMap[K, V](nil)
+	}
+}
+
+// UnmarshalJSON decodes into the map as described for QuickMap.UnmarshalJSON,
+// appending new keys in the order they appear. A zero LinkedQuickMap is
+// first initialised as if by NewLinkedMap with no hasher and no options.
+func (m *LinkedQuickMap[K, V]) UnmarshalJSON(data []byte) error {
+	return m.DecodeJSON(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeJSON reads the next JSON value from dec into the map, as described
+// for UnmarshalJSON
+func (m *LinkedQuickMap[K, V]) DecodeJSON(dec *json.Decoder) error {
+	m.initZero()
+	return m.QuickMap.DecodeJSON(dec)
+}
+
+// ReadFrom replaces the contents of the map with a snapshot, as described
+// for QuickMap.ReadFrom, in the order the snapshot holds them. A zero
+// LinkedQuickMap is first initialised as if by NewLinkedMap with no hasher
+// and no options.
+func (m *LinkedQuickMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	m.initZero()
+	return m.QuickMap.ReadFrom(r)
+}
+
+// MoveToFront makes key the first entry in iteration order, and reports
+// whether the key was present
+func (m *LinkedQuickMap[K, V]) MoveToFront(key K) bool {
+	m.detach()
+	t := m.linked()
+	n := *t.find(key, m.hash(key))
+	if n == nil {
+		return false
+	}
+	t.unlinkOrder(n)
+	t.pushFront(n)
+	m.checkInvariants()
+	return true
+}
+
+// MoveToBack makes key the last entry in iteration order, and reports
+// whether the key was present
+func (m *LinkedQuickMap[K, V]) MoveToBack(key K) bool {
+	m.detach()
+	t := m.linked()
+	n := *t.find(key, m.hash(key))
+	if n == nil {
+		return false
+	}
+	t.unlinkOrder(n)
+	t.pushBack(n)
+	m.checkInvariants()
+	return true
+}
+
+// First returns the first entry in iteration order, or false if the map is empty
+func (m *LinkedQuickMap[K, V]) First() (K, V, bool) {
+	return entryOf(m.linked().head)
+}
+
+// Last returns the last entry in iteration order, or false if the map is empty
+func (m *LinkedQuickMap[K, V]) Last() (K, V, bool) {
+	return entryOf(m.linked().tail)
+}
+
+// PopFirst removes and returns the first entry in iteration order, or false
+// if the map is empty
+func (m *LinkedQuickMap[K, V]) PopFirst() (K, V, bool) {
+	return m.pop(m.linked().head)
+}
+
+// PopLast removes and returns the last entry in iteration order, or false if
+// the map is empty
+func (m *LinkedQuickMap[K, V]) PopLast() (K, V, bool) {
+	return m.pop(m.linked().tail)
+}
+
+func (m *LinkedQuickMap[K, V]) pop(n *linkedNode[K, V]) (K, V, bool) {
+	key, value, ok := entryOf(n)
+	if ok {
+		m.Delete(key)
+	}
+	return key, value, ok
+}
+
+func entryOf[K comparable, V any](n *linkedNode[K, V]) (K, V, bool) {
+	if n == nil {
+		var zeroKey K
+		var zeroValue V
+		return zeroKey, zeroValue, false
+	}
+	return n.key, n.value, true
+}
+
+type linkedNode[K comparable, V any] struct {
+	key   K
+	value V
+	next  *linkedNode[K, V] // next node in the same bucket
+
+	// before and after link every node in the table in iteration order
+	before, after *linkedNode[K, V]
+}
+
+// linkedTable is the table behind a LinkedQuickMap: chained buckets like
+// chainTable, plus a list of all nodes from head to tail in iteration order
+type linkedTable[K comparable, V any] struct {
+	buckets    []*linkedNode[K, V]
+	head, tail *linkedNode[K, V]
+	size       int
+	hash       func(K) uint64
+
+	rehashStats
+}
+
+func newLinkedTable[K comparable, V any](initialCapacity int, hash func(K) uint64) *linkedTable[K, V] {
+	return &linkedTable[K, V]{
+		buckets: make([]*linkedNode[K, V], initialCapacity),
+		hash:    hash,
+	}
+}
+
+// find returns the link that points at the node for key, or the nil link at
+// the end of its bucket's chain
+func (t *linkedTable[K, V]) find(key K, h uint64) **linkedNode[K, V] {
+	link := &t.buckets[h%uint64(len(t.buckets))]
+	for *link != nil && (*link).key != key {
+		link = &(*link).next
+	}
+	return link
+}
+
+func (t *linkedTable[K, V]) pushFront(n *linkedNode[K, V]) {
+	n.before, n.after = nil, t.head
+	if t.head != nil {
+		t.head.before = n
+	} else {
+		t.tail = n
+	}
+	t.head = n
+}
+
+func (t *linkedTable[K, V]) pushBack(n *linkedNode[K, V]) {
+	n.before, n.after = t.tail, nil
+	if t.tail != nil {
+		t.tail.after = n
+	} else {
+		t.head = n
+	}
+	t.tail = n
+}
+
+// unlinkOrder removes n from the iteration order list only
+func (t *linkedTable[K, V]) unlinkOrder(n *linkedNode[K, V]) {
+	if n.before != nil {
+		n.before.after = n.after
+	} else {
+		t.head = n.after
+	}
+	if n.after != nil {
+		n.after.before = n.before
+	} else {
+		t.tail = n.before
+	}
+	// n keeps its own links, so that an iteration standing on a removed node
+	// can still step past it
+}
+
+// add appends a new node at the nil link returned by find
+func (t *linkedTable[K, V]) add(link **linkedNode[K, V], key K, value V) {
+	n := &linkedNode[K, V]{key: key, value: value}
+	*link = n
+	t.pushBack(n)
+	t.size++
+	if float64(t.size)/float64(len(t.buckets)) > loadFactor {
+		t.rehash(len(t.buckets) * 2)
+	}
+}
+
+// unlink removes the node at link from both its chain and the order list
+func (t *linkedTable[K, V]) unlink(link **linkedNode[K, V]) {
+	n := *link
+	*link = n.next
+	t.unlinkOrder(n)
+	t.size--
+}
+
+func (t *linkedTable[K, V]) insert(key K, h uint64, value V) {
+	link := t.find(key, h)
+	if n := *link; n != nil {
+		n.value = value
+		return
+	}
+	t.add(link, key, value)
+}
+
+func (t *linkedTable[K, V]) get(key K, h uint64) (V, bool) {
+	if n := *t.find(key, h); n != nil {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (t *linkedTable[K, V]) remove(key K, h uint64) {
+	if link := t.find(key, h); *link != nil {
+		t.unlink(link)
+	}
+}
+
+// removeFunc calls f in iteration order
+func (t *linkedTable[K, V]) removeFunc(f func(key K, value V) bool) {
+	for n := t.head; n != nil; {
+		after := n.after
+		if f(n.key, n.value) {
+			t.unlink(t.find(n.key, t.hash(n.key)))
+		}
+		n = after
+	}
+}
+
+func (t *linkedTable[K, V]) compute(key K, h uint64, f func(old V, exists bool) (V, bool)) (V, bool) {
+	var zero V
+	link := t.find(key, h)
+	if n := *link; n != nil {
+		value, keep := f(n.value, true)
+		if !keep {
+			t.unlink(link)
+			return zero, false
+		}
+		n.value = value
+		return value, true
+	}
+
+	value, keep := f(zero, false)
+	if !keep {
+		return zero, false
+	}
+	t.add(link, key, value)
+	return value, true
+}
+
+func (t *linkedTable[K, V]) len() int {
+	return t.size
+}
+
+func (t *linkedTable[K, V]) capacity() int {
+	return len(t.buckets)
+}
+
+func (t *linkedTable[K, V]) forEach(f func(key K, value V) bool) {
+	for n := t.head; n != nil; n = n.after {
+		if !f(n.key, n.value) {
+			return
+		}
+	}
+}
+
+func (t *linkedTable[K, V]) clone() table[K, V] {
+	c := newLinkedTable[K, V](len(t.buckets), t.hash)
+	for n := t.head; n != nil; n = n.after {
+		link := &c.buckets[t.hash(n.key)%uint64(len(c.buckets))]
+		copied := &linkedNode[K, V]{key: n.key, value: n.value, next: *link}
+		*link = copied
+		c.pushBack(copied)
+	}
+	c.size = t.size
+	c.rehashStats = t.rehashStats
+	return c
+}
+
+func (t *linkedTable[K, V]) reserve(n int) {
+	if n > int(float64(len(t.buckets))*loadFactor) {
+		newCapacity := len(t.buckets) * 2
+		for newCapacity < n {
+			newCapacity *= 2
+		}
+		t.rehash(newCapacity)
+	}
+}
+
+func (t *linkedTable[K, V]) compact() {
+	if newCapacity := minChainCapacity(t.size); newCapacity < len(t.buckets) {
+		t.rehash(newCapacity)
+	}
+}
+
+// rehash rebuilds the bucket chains for newCapacity buckets. The order list
+// is left as it is.
+func (t *linkedTable[K, V]) rehash(newCapacity int) {
+	defer t.rehashed(time.Now())
+	t.buckets = make([]*linkedNode[K, V], newCapacity)
+	for n := t.head; n != nil; n = n.after {
+		index := t.hash(n.key) % uint64(newCapacity)
+		n.next = t.buckets[index]
+		t.buckets[index] = n
+	}
+}
@@ -0,0 +1,154 @@
+package quickmap
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestLinkedQuickMap(t *testing.T) {
+	// Test that iteration follows insertion order across resizes
+	t.Run("Insertion order", func(t *testing.T) {
+		m := NewLinkedMapWithCapacity[string, int](nil, 1)
+		expected := make([]string, 0, 1000)
+		for i := 999; i >= 0; i-- {
+			key := strconv.Itoa(i)
+			m.Insert(key, i)
+			expected = append(expected, key)
+		}
+		m.Insert("500", -1)
+		if keys := slices.Collect(m.Keys()); !slices.Equal(keys, expected) {
+			t.Errorf("Keys() is not in insertion order after %d resizes", m.Size())
+		}
+		m.Delete("999")
+		m.Insert("999", 999)
+		expected = append(expected[1:], "999")
+		order := make([]string, 0, 1000)
+		m.ForEach(func(key string, value int) { order = append(order, key) })
+		if !slices.Equal(order, expected) {
+			t.Errorf("ForEach is not in insertion order after re-inserting a deleted key")
+		}
+		if value, _ := m.Get("500"); value != -1 {
+			t.Errorf("Get(\"500\") = %d, expected -1", value)
+		}
+	})
+
+	// Test First, Last, MoveToFront, MoveToBack, PopFirst and PopLast
+	t.Run("Ordered operations", func(t *testing.T) {
+		m := NewLinked()
+		if _, _, ok := m.First(); ok {
+			t.Errorf("First() returned true on an empty map, expected false")
+		}
+		if _, _, ok := m.PopLast(); ok {
+			t.Errorf("PopLast() returned true on an empty map, expected false")
+		}
+		for _, key := range []string{"a", "b", "c", "d"} {
+			m.Insert(key, key)
+		}
+		if !m.MoveToFront("c") || !m.MoveToBack("a") || m.MoveToFront("missing") {
+			t.Errorf("MoveToFront and MoveToBack did not report presence correctly")
+		}
+		if keys := slices.Collect(m.Keys()); !slices.Equal(keys, []string{"c", "b", "d", "a"}) {
+			t.Errorf("Keys() = %v, expected [c b d a]", keys)
+		}
+		if key, value, ok := m.First(); !ok || key != "c" || value != "c" {
+			t.Errorf("First() = %q, %v, %t; expected \"c\", \"c\", true", key, value, ok)
+		}
+		if key, _, ok := m.Last(); !ok || key != "a" {
+			t.Errorf("Last() = %q, %t; expected \"a\", true", key, ok)
+		}
+		if key, _, ok := m.PopFirst(); !ok || key != "c" {
+			t.Errorf("PopFirst() = %q, %t; expected \"c\", true", key, ok)
+		}
+		if key, _, ok := m.PopLast(); !ok || key != "a" {
+			t.Errorf("PopLast() = %q, %t; expected \"a\", true", key, ok)
+		}
+		if keys := slices.Collect(m.Keys()); m.Size() != 2 || !slices.Equal(keys, []string{"b", "d"}) {
+			t.Errorf("Keys() = %v after popping, expected [b d]", keys)
+		}
+	})
+
+	// Test the QuickMap methods inherited through the embedded map
+	t.Run("QuickMap methods", func(t *testing.T) {
+		m := NewLinkedMap[int, int](nil, WithShrinkFactor(0.1))
+		for i := 0; i < 1000; i++ {
+			m.Insert(i, i)
+		}
+		peak := m.t.capacity()
+		var called []int
+		m.DeleteFunc(func(key, value int) bool {
+			called = append(called, key)
+			return key >= 10
+		})
+		if len(called) != 1000 || !slices.IsSorted(called) {
+			t.Errorf("DeleteFunc was not called once per key in insertion order")
+		}
+		if m.Size() != 10 || m.t.capacity() >= peak {
+			t.Errorf("Size() = %d, capacity %d after DeleteFunc; expected 10 and below %d", m.Size(), m.t.capacity(), peak)
+		}
+		m.Compute(100, func(old int, exists bool) (int, bool) { return 100, true })
+		m.Swap(0, -1)
+		if keys := slices.Collect(m.Keys()); !slices.Equal(keys, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 100}) {
+			t.Errorf("Keys() = %v, expected 0 to 9 then 100", keys)
+		}
+		m.Compact()
+		if value, exists := m.Get(0); !exists || value != -1 {
+			t.Errorf("Get(0) = %d, %t after Compact; expected -1, true", value, exists)
+		}
+	})
+
+	// Test reordering while iterating
+	t.Run("Mutation during iteration", func(t *testing.T) {
+		m := NewLinkedMap[int, int](nil)
+		for i := 0; i < 100; i++ {
+			m.Insert(i, i)
+		}
+		visited := 0
+		for key := range m.Keys() {
+			visited++
+			m.MoveToFront(key)
+			m.Delete(key + 1)
+			m.Insert(key+1000, key)
+		}
+		if visited != 50 {
+			t.Errorf("Visited %d keys, expected 50", visited)
+		}
+		if key, _, ok := m.First(); !ok || key != 98 {
+			t.Errorf("First() = %d, %t; expected 98, true", key, ok)
+		}
+	})
+
+	// Test that decoding into a zero LinkedQuickMap sets up a linked table
+	// and keeps the decoded order
+	t.Run("Zero map", func(t *testing.T) {
+		var m LinkedQuickMap[string, int]
+		if err := m.UnmarshalJSON([]byte(`{"b": 1, "a": 2, "c": 3}`)); err != nil {
+			t.Fatalf("UnmarshalJSON returned %v", err)
+		}
+		if keys := slices.Collect(m.Keys()); !slices.Equal(keys, []string{"b", "a", "c"}) {
+			t.Errorf("Keys() = %v after UnmarshalJSON, expected [b a c]", keys)
+		}
+		if !m.MoveToFront("c") {
+			t.Errorf("MoveToFront(\"c\") = false, expected true")
+		}
+		if key, _, ok := m.First(); !ok || key != "c" {
+			t.Errorf("First() = %q, %t; expected \"c\", true", key, ok)
+		}
+
+		var buf bytes.Buffer
+		if _, err := m.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo returned %v", err)
+		}
+		var read LinkedQuickMap[string, int]
+		if _, err := read.ReadFrom(&buf); err != nil {
+			t.Fatalf("ReadFrom returned %v", err)
+		}
+		if keys := slices.Collect(read.Keys()); !slices.Equal(keys, []string{"c", "b", "a"}) {
+			t.Errorf("Keys() = %v after ReadFrom, expected [c b a]", keys)
+		}
+		if key, _, ok := read.Last(); !ok || key != "a" {
+			t.Errorf("Last() = %q, %t; expected \"a\", true", key, ok)
+		}
+	})
+}
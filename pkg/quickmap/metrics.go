@@ -0,0 +1,102 @@
+package quickmap
+
+import "sync/atomic"
+
+// Metrics counts the operations on the maps given it by WithMetrics, and
+// tracks their combined size and capacity. Its zero value is ready to use.
+// It is updated atomically, so it can be read with Snapshot while the maps
+// are in use, and the package quickmetrics publishes it through expvar and
+// Prometheus. Maps without metrics pay only a nil check per operation.
+type Metrics struct {
+	inserts atomic.Uint64
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	deletes atomic.Uint64
+	resizes atomic.Uint64
+	size    atomic.Int64
+	buckets atomic.Int64
+}
+
+// MetricsSnapshot holds the values of a Metrics at one moment
+type MetricsSnapshot struct {
+	Inserts    uint64  // values stored by Insert, InsertMany and Compute
+	Hits       uint64  // Get calls that found their key
+	Misses     uint64  // Get calls that did not
+	Deletes    uint64  // entries removed, by any method
+	Resizes    uint64  // table rebuilds, as counted by Stats
+	Size       int64   // entries held
+	Buckets    int64   // buckets, or slots, as counted by Stats
+	LoadFactor float64 // Size divided by Buckets
+}
+
+// Snapshot returns the current values of m. The values are read one at a
+// time, so under concurrent use they may be slightly out of step.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	s := MetricsSnapshot{
+		Inserts: m.inserts.Load(),
+		Hits:    m.hits.Load(),
+		Misses:  m.misses.Load(),
+		Deletes: m.deletes.Load(),
+		Resizes: m.resizes.Load(),
+		Size:    m.size.Load(),
+		Buckets: m.buckets.Load(),
+	}
+	if s.Buckets > 0 {
+		s.LoadFactor = float64(s.Size) / float64(s.Buckets)
+	}
+	return s
+}
+
+// tableState is what a map's metrics are derived from. Operations compare the
+// state before and after, so that size, capacity and rebuilds are counted
+// however they came about.
+type tableState struct {
+	size, capacity, rebuilds int
+}
+
+// state returns the state of the table for record, or nothing if the map has
+// no metrics
+func (m *QuickMap[K, V]) state() tableState {
+	if m.metrics == nil {
+		return tableState{}
+	}
+	return tableState{m.t.len(), m.t.capacity(), m.t.rebuilds()}
+}
+
+// record adds the changes since before to the map's metrics, if it has any,
+// counting an insert if inserted is set
+func (m *QuickMap[K, V]) record(before tableState, inserted bool) {
+	mt := m.metrics
+	if mt == nil {
+		return
+	}
+	after := m.state()
+	if inserted {
+		mt.inserts.Add(1)
+	}
+	if removed := before.size - after.size; removed > 0 {
+		mt.deletes.Add(uint64(removed))
+	}
+	// A table replaced by ReadFrom starts counting rebuilds from zero
+	if rebuilt := after.rebuilds - before.rebuilds; rebuilt > 0 {
+		mt.resizes.Add(uint64(rebuilt))
+	}
+	mt.size.Add(int64(after.size - before.size))
+	mt.buckets.Add(int64(after.capacity - before.capacity))
+}
+
+// recordGet counts a lookup. Only call it when the map has metrics.
+func (m *QuickMap[K, V]) recordGet(found bool) {
+	if found {
+		m.metrics.hits.Add(1)
+	} else {
+		m.metrics.misses.Add(1)
+	}
+}
+
+// trackTable adds a new table to the map's metrics
+func (m *QuickMap[K, V]) trackTable() {
+	if m.metrics != nil {
+		m.record(tableState{rebuilds: m.t.rebuilds()}, false)
+	}
+}
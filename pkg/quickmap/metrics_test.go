@@ -0,0 +1,133 @@
+package quickmap
+
+import (
+	"bytes"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	// Test the counters of each kind of operation
+	t.Run("Counters", func(t *testing.T) {
+		var metrics Metrics
+		m := NewMap[string, int](nil, WithMetrics(&metrics))
+		m.Insert("a", 1)
+		m.Insert("b", 2)
+		m.Insert("a", 3)
+		m.Get("a")
+		m.Get("c")
+		m.Delete("b")
+		m.Delete("b")
+		m.InsertMany(map[string]int{"d": 4, "e": 5})
+		m.DeleteMany([]string{"d", "x"})
+		m.Update("a", func(old int) int { return old + 1 })
+		m.LoadAndDelete("e")
+
+		s := metrics.Snapshot()
+		expected := MetricsSnapshot{Inserts: 6, Hits: 1, Misses: 1, Deletes: 3, Size: 1, Buckets: defaultInitialSize, LoadFactor: 1.0 / defaultInitialSize}
+		if s != expected {
+			t.Errorf("Snapshot() = %+v, expected %+v", s, expected)
+		}
+	})
+
+	// Test that the gauges and resizes follow the table on every backend
+	t.Run("Gauges", func(t *testing.T) {
+		configs := map[string][]Option{
+			"Chaining":    {WithShrinkFactor(0.2)},
+			"Incremental": {WithIncrementalResize(1)},
+			"Swiss":       {WithBackend(Swiss), WithShrinkFactor(0.2)},
+			"RobinHood":   {WithBackend(RobinHood), WithShrinkFactor(0.2)},
+		}
+		for name, opts := range configs {
+			t.Run(name, func(t *testing.T) {
+				var metrics Metrics
+				m := NewMapWithCapacity[int, int](nil, 1, append(opts, WithMetrics(&metrics))...)
+				r := rand.New(rand.NewSource(1))
+				for i := 0; i < 20000; i++ {
+					if key := r.Intn(2000); i%5000 < 3000 {
+						m.Insert(key, i)
+					} else {
+						m.Delete(key)
+					}
+				}
+				m.Compact()
+				checkGauges(t, &metrics, m)
+				if metrics.Snapshot().Resizes == 0 {
+					t.Errorf("Snapshot() = 0 resizes after growing from capacity 1, expected more")
+				}
+			})
+		}
+		t.Run("Linked", func(t *testing.T) {
+			var metrics Metrics
+			m := NewLinkedMapWithCapacity[int, int](nil, 1, WithMetrics(&metrics))
+			for i := 0; i < 1000; i++ {
+				m.Insert(i, i)
+			}
+			checkGauges(t, &metrics, &m.QuickMap)
+		})
+	})
+
+	// Test that replacing the table with ReadFrom moves the gauges
+	t.Run("ReadFrom", func(t *testing.T) {
+		source := NewMap[int, int](nil)
+		for i := 0; i < 1000; i++ {
+			source.Insert(i, i)
+		}
+		var buf bytes.Buffer
+		source.WriteTo(&buf)
+
+		var metrics Metrics
+		m := NewMap[int, int](nil, WithMetrics(&metrics))
+		m.Insert(-1, -1)
+		if _, err := m.ReadFrom(&buf); err != nil {
+			t.Fatalf("ReadFrom returned %v, expected nil", err)
+		}
+		checkGauges(t, &metrics, m)
+	})
+
+	// Test shards sharing one Metrics
+	t.Run("Concurrent", func(t *testing.T) {
+		var metrics Metrics
+		m := NewConcurrentMap[int, int](nil, WithShards(4), WithMetrics(&metrics))
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					m.Insert(g*1000+i, i)
+					m.Get(g*1000 + i)
+				}
+				m.DeleteMany([]int{g * 1000, g*1000 + 1})
+			}(g)
+		}
+		wg.Wait()
+		m.InsertMany(map[int]int{-1: 1, -2: 2})
+
+		s := metrics.Snapshot()
+		buckets := 0
+		for i := range m.shards {
+			buckets += m.shards[i].m.t.capacity()
+		}
+		if s.Size != int64(m.Size()) || s.Buckets != int64(buckets) {
+			t.Errorf("Snapshot() = size %d in %d buckets, expected %d in %d", s.Size, s.Buckets, m.Size(), buckets)
+		}
+		if s.Inserts != 4002 || s.Hits != 4000 || s.Deletes != 8 {
+			t.Errorf("Snapshot() = %d inserts, %d hits, %d deletes; expected 4002, 4000, 8", s.Inserts, s.Hits, s.Deletes)
+		}
+	})
+}
+
+// checkGauges checks the gauges and resize count of metrics against the
+// statistics of m, its only map
+func checkGauges(t *testing.T, metrics *Metrics, m *QuickMap[int, int]) {
+	t.Helper()
+	s, stats := metrics.Snapshot(), m.Stats()
+	if s.Size != int64(stats.Size) || s.Buckets != int64(stats.Buckets) {
+		t.Errorf("Snapshot() = size %d in %d buckets, expected %d in %d", s.Size, s.Buckets, stats.Size, stats.Buckets)
+	}
+	if s.Resizes != uint64(stats.Resizes) {
+		t.Errorf("Snapshot() = %d resizes, expected %d", s.Resizes, stats.Resizes)
+	}
+}
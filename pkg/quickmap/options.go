@@ -0,0 +1,176 @@
+package quickmap
+
+import (
+	"strconv"
+
+	"github.com/marpit19/goquickmap/internal/hash"
+)
+
+const defaultResizeStep = 8
+
+// Option configures a QuickMap at construction time
+type Option func(*options)
+
+type options struct {
+	seed     uint64
+	seeded   bool
+	hashFunc Hasher[string]
+	backend  Backend
+	// resizeStep is the number of buckets migrated per operation during an
+	// incremental resize, or 0 to resize in a single step
+	resizeStep int
+	// shrinkFactor is the load below which deletions compact the table
+	shrinkFactor float64
+	// shards is the number of shards in a ConcurrentQuickMap
+	shards int
+	// sortedJSON makes MarshalJSON sort keys
+	sortedJSON bool
+	// keyCodec and valueCodec hold the Codec[K] and Codec[V] for snapshots,
+	// checked against the map's types by configure
+	keyCodec, valueCodec any
+	// metrics receives the map's operation counts, if set
+	metrics *Metrics
+}
+
+// Backend selects the table layout behind a QuickMap
+type Backend int
+
+const (
+	// Chaining stores each entry in its own node, linked into per-bucket lists
+	Chaining Backend = iota
+	// Swiss stores entries inline in an open-addressed table probed a group of
+	// eight slots at a time, using one control byte per slot
+	Swiss
+	// RobinHood stores keys, hashes and values in flat arrays probed linearly,
+	// evening out probe lengths and deleting without tombstones
+	RobinHood
+)
+
+func (b Backend) String() string {
+	switch b {
+	case Chaining:
+		return "chaining"
+	case Swiss:
+		return "swiss"
+	case RobinHood:
+		return "robinhood"
+	}
+	return "Backend(" + strconv.Itoa(int(b)) + ")"
+}
+
+// WithSeed pins the seed passed to the map's Hasher instead of drawing a random
+// one. Pinned seeds make bucket placement reproducible, which is useful in
+// tests, but give up the protection against crafted collisions.
+func WithSeed(seed uint64) Option {
+	return func(o *options) {
+		o.seed = seed
+		o.seeded = true
+	}
+}
+
+// WithHashFunc selects the registered string Hasher with the given name:
+// "siphash" (the default), "fnv1", "wyhash", "maphash", or any name added
+// with RegisterHasher. It applies to maps with string keys that were not given
+// an explicit Hasher, and panics at construction if the name is unknown.
+func WithHashFunc(name string) Option {
+	return func(o *options) {
+		h, ok := LookupHasher(name)
+		if !ok {
+			panic("quickmap: unknown hash function " + name)
+		}
+		o.hashFunc = h
+	}
+}
+
+// WithBackend selects the table layout. The default is Chaining.
+func WithBackend(b Backend) Option {
+	return func(o *options) {
+		o.backend = b
+	}
+}
+
+// WithIncrementalResize makes the Chaining backend resize incrementally: when
+// the map outgrows its buckets, the old and new bucket arrays are both kept
+// and every Insert, Get and Delete moves bucketsPerStep buckets across,
+// bounding the latency of any single call. If the map could otherwise
+// outgrow the new array before every bucket has moved, each call moves
+// enough more that it cannot. Lookups check whichever array still holds the
+// key's bucket. A bucketsPerStep below 1 selects a default
+// of 8. The Swiss and RobinHood backends ignore this option.
+func WithIncrementalResize(bucketsPerStep int) Option {
+	return func(o *options) {
+		if bucketsPerStep < 1 {
+			bucketsPerStep = defaultResizeStep
+		}
+		o.resizeStep = bucketsPerStep
+	}
+}
+
+// WithShrinkFactor makes Delete and DeleteMany compact the map once its load
+// (entries per unit of capacity) falls below factor. The factor must lie in
+// [0, 0.25], leaving room below the load a map has right after growing so
+// that alternating inserts and deletes cannot make it resize back and forth.
+// It panics otherwise. The default of 0 never shrinks automatically.
+func WithShrinkFactor(factor float64) Option {
+	if factor < 0 || factor > 0.25 {
+		panic("quickmap: shrink factor must be between 0 and 0.25")
+	}
+	return func(o *options) {
+		o.shrinkFactor = factor
+	}
+}
+
+// WithShards sets the number of independently locked shards in a
+// ConcurrentQuickMap, rounded up to a power of two. The default is four
+// shards per GOMAXPROCS. Other maps ignore this option.
+func WithShards(n int) Option {
+	return func(o *options) {
+		o.shards = n
+	}
+}
+
+// WithSortedJSON makes MarshalJSON sort keys, as encoding/json does for Go
+// maps, so that equal maps always encode to the same bytes. Sets are sorted by
+// element. Without it entries are written in iteration order.
+func WithSortedJSON() Option {
+	return func(o *options) {
+		o.sortedJSON = true
+	}
+}
+
+// WithKeyCodec sets the Codec that WriteTo and ReadFrom use for keys. The
+// codec must be for the map's key type, or the constructor panics.
+func WithKeyCodec[K any](c Codec[K]) Option {
+	return func(o *options) {
+		o.keyCodec = c
+	}
+}
+
+// WithValueCodec sets the Codec that WriteTo and ReadFrom use for values. The
+// codec must be for the map's value type, or the constructor panics.
+func WithValueCodec[V any](c Codec[V]) Option {
+	return func(o *options) {
+		o.valueCodec = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.seeded {
+		o.seed = hash.NewSeed()
+	}
+	return o
+}
+
+// WithMetrics makes the map count its operations, size and capacity in m.
+// Several maps may share one Metrics, which then holds their totals; the
+// shards of a ConcurrentQuickMap always do. AtomicQuickMap ignores this
+// option.
+func WithMetrics(m *Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
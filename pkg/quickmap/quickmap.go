@@ -1,160 +1,310 @@
 package quickmap
 
-import "github.com/marpit19/goquickmap/internal/hash"
-
 const (
 	defaultInitialSize = 16
 	loadFactor         = 0.75
 )
 
-type node struct {
-	key   string
-	value interface{}
-	next  *node
+// table is the storage behind a QuickMap. Keys arrive already hashed; the
+// table keeps a hash function only to rehash its entries when it grows.
+type table[K comparable, V any] interface {
+	insert(key K, h uint64, value V)
+	get(key K, h uint64) (V, bool)
+	remove(key K, h uint64)
+	// removeFunc removes every entry for which f returns true in a single
+	// pass, calling f once per entry
+	removeFunc(f func(key K, value V) bool)
+	// compute finds key in a single probe and calls f with its current value,
+	// if any. The key is then stored with the value f returns, or removed if
+	// f returns false. compute returns the key's value afterwards and whether
+	// it is present.
+	compute(key K, h uint64, f func(old V, exists bool) (V, bool)) (V, bool)
+	len() int
+	capacity() int
+	// forEach calls f for each entry until f returns false. f may look
+	// entries up but must not modify the table.
+	forEach(f func(key K, value V) bool)
+	// clone returns a copy of the table that shares no mutable state with it
+	clone() table[K, V]
+	// reserve grows the table so that it holds n entries without resizing
+	reserve(n int)
+	// compact rebuilds the table at the smallest capacity that holds its
+	// entries, unless it is already that small
+	compact()
+	// validate checks the table's invariants, placing keys with hash
+	validate(hash func(K) uint64) error
+	// rebuilds returns the number of times the table has been rebuilt
+	rebuilds() int
+	// stats returns the layout statistics of the table, leaving Backend, Size
+	// and LoadFactor to the map
+	stats() Stats
 }
 
 // QuickMap represents a hash table
-type QuickMap struct {
-	buckets []*node
-	size    int
+type QuickMap[K comparable, V any] struct {
+	t            table[K, V]
+	hasher       Hasher[K]
+	seed         uint64
+	shrinkFactor float64
+	sortedJSON   bool
+	keyCodec     Codec[K]
+	valueCodec   Codec[V]
+	metrics      *Metrics
+	// listeners is nil unless the map has subscribers; see Subscribe
+	listeners *listeners[K, V]
+
+	// walkers counts the iterations in progress over t. While it is
+	// non-zero, changes that could move entries are made to a copy of t
+	// instead; see detach.
+	walkers int
+	// unlinks counts the removals made from t in place while walkers was
+	// non-zero; see detachForRemove
+	unlinks int
 }
 
+// StringMap is a QuickMap with string keys and untyped values, as returned by New
+type StringMap = QuickMap[string, interface{}]
+
 // creates and returns  a new QuickMap
-func New() *QuickMap {
-	return NewWithCapacity(defaultInitialSize)
+func New(opts ...Option) *StringMap {
+	return NewWithCapacity(defaultInitialSize, opts...)
 }
 
 // NewWithCapacity creates and returns a new QuickMap with the specified initial capacity
-func NewWithCapacity(initialCapacity int) *QuickMap {
-	if initialCapacity < 1 {
-		initialCapacity = defaultInitialSize
+func NewWithCapacity(initialCapacity int, opts ...Option) *StringMap {
+	return NewMapWithCapacity[string, interface{}](nil, initialCapacity, opts...)
+}
+
+// NewMap creates and returns a new QuickMap that hashes its keys with hasher.
+// A nil hasher selects the WithHashFunc hasher for string keys and
+// DefaultHasher for K otherwise.
+func NewMap[K comparable, V any](hasher Hasher[K], opts ...Option) *QuickMap[K, V] {
+	return NewMapWithCapacity[K, V](hasher, defaultInitialSize, opts...)
+}
+
+// NewMapWithCapacity creates and returns a new QuickMap with the specified hasher and initial capacity
+func NewMapWithCapacity[K comparable, V any](hasher Hasher[K], initialCapacity int, opts ...Option) *QuickMap[K, V] {
+	o := newOptions(opts)
+	return newMap[K, V](resolveHasher(hasher, o), initialCapacity, o)
+}
+
+// resolveHasher applies the rules for a nil hasher described on NewMap
+func resolveHasher[K comparable](hasher Hasher[K], o options) Hasher[K] {
+	if hasher != nil {
+		return hasher
 	}
-	return &QuickMap{
-		buckets: make([]*node, initialCapacity),
-		size:    0,
+	if h, ok := any(o.hashFunc).(Hasher[K]); ok && h != nil {
+		return h
 	}
+	return DefaultHasher[K]()
 }
 
-// Insert adds a new key-value pair to our map
-func (m *QuickMap) Insert(key string, value interface{}) {
-	index := hash.Hash(key) % uint64(len(m.buckets))
-	newNode := &node{key: key, value: value}
-
-	if m.buckets[index] == nil {
-		m.buckets[index] = newNode
-	} else {
-		current := m.buckets[index]
-		for current.next != nil {
-			if current.key == key {
-				current.value = value
-				return
-			}
-			current = current.next
-		}
-		if current.key == key {
-			current.value = value
-		} else {
-			current.next = newNode
-		}
+func newMap[K comparable, V any](hasher Hasher[K], initialCapacity int, o options) *QuickMap[K, V] {
+	if initialCapacity < 1 {
+		initialCapacity = defaultInitialSize
 	}
-	m.size++
+	m := &QuickMap[K, V]{}
+	m.init(hasher, initialCapacity, o)
+	return m
+}
 
-	if float64(m.size)/float64(len(m.buckets)) > loadFactor {
-		m.resize(m.size * 2)
+// init sets up a zero QuickMap in place
+func (m *QuickMap[K, V]) init(hasher Hasher[K], initialCapacity int, o options) {
+	m.configure(hasher, o)
+	switch o.backend {
+	case Swiss:
+		m.t = newSwissTable[K, V](initialCapacity, m.hash)
+	case RobinHood:
+		m.t = newRobinTable[K, V](initialCapacity)
+	default:
+		m.t = newChainTable[K, V](initialCapacity, m.hash, o.resizeStep)
 	}
+	m.trackTable()
 }
 
-// Get retrieves a value by key
-func (m *QuickMap) Get(key string) (interface{}, bool) {
-	index := hash.Hash(key) % uint64(len(m.buckets))
-	current := m.buckets[index]
-
-	for current != nil {
-		if current.key == key {
-			return current.value, true
-		}
-		current = current.next
-	}
+// configure applies everything but the table from the options
+func (m *QuickMap[K, V]) configure(hasher Hasher[K], o options) {
+	m.hasher = hasher
+	m.seed = o.seed
+	m.shrinkFactor = o.shrinkFactor
+	m.sortedJSON = o.sortedJSON
+	m.keyCodec = codecFor[K](o.keyCodec, "WithKeyCodec")
+	m.valueCodec = codecFor[V](o.valueCodec, "WithValueCodec")
+	m.metrics = o.metrics
+}
 
-	return nil, false
+func (m *QuickMap[K, V]) hash(key K) uint64 {
+	return m.hasher(key, m.seed)
 }
 
-// Delete removes a key-value pair from the map
-func (m *QuickMap) Delete(key string) {
-	index := hash.Hash(key) % uint64(len(m.buckets))
-	if m.buckets[index] == nil {
-		return
+// Insert adds a new key-value pair to our map
+func (m *QuickMap[K, V]) Insert(key K, value V) {
+	if m.metrics != nil {
+		defer m.record(m.state(), true)
 	}
-
-	if m.buckets[index].key == key {
-		m.buckets[index] = m.buckets[index].next
-		m.size--
-		return
+	h := m.hash(key)
+	if m.listeners != nil {
+		old, existed := m.t.get(key, h)
+		defer m.notifyInsert(key, value, old, existed, m.t.capacity())
 	}
-
-	current := m.buckets[index]
-	for current.next != nil {
-		if current.next.key == key {
-			current.next = current.next.next
-			m.size--
+	if m.walkers > 0 {
+		// Overwriting a value in place leaves the table's layout alone
+		if _, ok := m.t.get(key, h); ok {
+			m.t.compute(key, h, func(V, bool) (V, bool) { return value, true })
+			m.checkInvariants()
 			return
 		}
+		m.detach()
+	}
+	m.t.insert(key, h, value)
+	m.checkInvariants()
+}
 
-		current = current.next
+// Get retrieves a value by key
+func (m *QuickMap[K, V]) Get(key K) (V, bool) {
+	value, ok := m.t.get(key, m.hash(key))
+	if m.metrics != nil {
+		m.recordGet(ok)
+	}
+	return value, ok
+}
+
+// Delete removes a key-value pair from the map
+func (m *QuickMap[K, V]) Delete(key K) {
+	if m.metrics != nil {
+		defer m.record(m.state(), false)
+	}
+	h := m.hash(key)
+	if m.listeners != nil {
+		old, existed := m.t.get(key, h)
+		defer m.notifyDelete(key, old, existed, m.t.capacity())
 	}
+	m.detachForRemove()
+	m.t.remove(key, h)
+	m.maybeShrink()
+	m.checkInvariants()
 }
 
 // Size returns the number of elements in the QuickMap
-func (m *QuickMap) Size() int {
-	return m.size
+func (m *QuickMap[K, V]) Size() int {
+	return m.t.len()
 }
 
-// ForEach iterates over all key-value pairs in the QuickMap and applies the given function
-func (m *QuickMap) ForEach(f func(key string, value interface{})) {
-	for _, bucket := range m.buckets {
-		current := bucket
-		for current != nil {
-			f(current.key, current.value)
-			current = current.next
-		}
-	}
+// ForEach iterates over all key-value pairs in the QuickMap and applies the
+// given function. f may modify the map, as described for All.
+func (m *QuickMap[K, V]) ForEach(f func(key K, value V)) {
+	m.iterate(func(key K, value V) bool {
+		f(key, value)
+		return true
+	})
 }
 
 // InsertMany adds multiple key-value pairs to the map
-func (m *QuickMap) InsertMany(pairs map[string]interface{}) {
+func (m *QuickMap[K, V]) InsertMany(pairs map[K]V) {
+	m.detach()
 	// Pre-allocate space if needed
-	if m.size+len(pairs) > int(float64(len(m.buckets))*loadFactor) {
-		m.resize(m.size + len(pairs))
-	}
+	before, capacity := m.state(), m.t.capacity()
+	m.t.reserve(m.t.len() + len(pairs))
+	m.record(before, false)
+	reserved := m.t.capacity()
 
 	for k, v := range pairs {
 		m.Insert(k, v)
 	}
+	// Report the pre-allocation after the inserts it was made for, unless an
+	// insert grew the table further and has reported that already
+	if m.listeners != nil && m.t.capacity() == reserved {
+		m.notifyResize(capacity)
+	}
+}
+
+// DeleteFunc removes every key-value pair for which del returns true, in a
+// single pass over the map. del must not modify the map.
+func (m *QuickMap[K, V]) DeleteFunc(del func(key K, value V) bool) {
+	if m.metrics != nil {
+		defer m.record(m.state(), false)
+	}
+	m.detachForRemove()
+	if m.listeners != nil {
+		m.removeFuncNotify(del)
+		return
+	}
+	m.t.removeFunc(del)
+	m.maybeShrink()
+	m.checkInvariants()
 }
 
 // DeleteMany removes multiple keys from the map
-func (m *QuickMap) DeleteMany(keys []string) {
+func (m *QuickMap[K, V]) DeleteMany(keys []K) {
+	if m.metrics != nil {
+		defer m.record(m.state(), false)
+	}
+	m.detachForRemove()
+	if m.listeners != nil {
+		capacity := m.t.capacity()
+		for _, k := range keys {
+			h := m.hash(k)
+			if old, existed := m.t.get(k, h); existed {
+				m.t.remove(k, h)
+				m.emit(Event[K, V]{Kind: Deleted, Key: k, Value: old})
+			}
+		}
+		m.maybeShrink()
+		m.notifyResize(capacity)
+		m.checkInvariants()
+		return
+	}
 	for _, k := range keys {
-		m.Delete(k)
+		m.t.remove(k, m.hash(k))
 	}
+	m.maybeShrink()
+	m.checkInvariants()
 }
 
-// resize increases the size of the hash table and reshases all the elements
-func (m *QuickMap) resize(targetSize int) {
-	newCapacity := len(m.buckets) * 2
-	for newCapacity < targetSize {
-		newCapacity *= 2
+// Compact rebuilds the map at the smallest capacity that holds its current
+// entries, releasing the memory left behind by deletions
+func (m *QuickMap[K, V]) Compact() {
+	if m.metrics != nil {
+		defer m.record(m.state(), false)
 	}
+	if m.listeners != nil {
+		defer m.notifyResize(m.t.capacity())
+	}
+	m.detach()
+	m.t.compact()
+	m.checkInvariants()
+}
 
-	newBuckets := make([]*node, newCapacity)
-	for _, bucket := range m.buckets {
-		for bucket != nil {
-			index := hash.Hash(bucket.key) % uint64(newCapacity)
-			next := bucket.next
-			bucket.next = newBuckets[index]
-			newBuckets[index] = bucket
-			bucket = next
-		}
+// detach gives the map a private copy of its table if an iteration is
+// walking the current one, so that changes cannot move entries under it
+func (m *QuickMap[K, V]) detach() {
+	if m.walkers > 0 {
+		m.t = m.t.clone()
+		m.walkers = 0
+	}
+}
+
+// detachForRemove is detach for changes that only remove entries. The
+// chaining tables unlink a removed node without moving any other, and leave
+// the node's own links alone, so an iteration standing on it can still step
+// past it. They are changed in place, and iterations are told to check each
+// entry they visit from then on. Other tables are copied.
+func (m *QuickMap[K, V]) detachForRemove() {
+	if m.walkers == 0 {
+		return
+	}
+	switch m.t.(type) {
+	case *chainTable[K, V], *linkedTable[K, V]:
+		m.unlinks++
+	default:
+		m.detach()
+	}
+}
+
+// maybeShrink compacts the table once its load drops below the WithShrinkFactor threshold
+func (m *QuickMap[K, V]) maybeShrink() {
+	if m.shrinkFactor > 0 && float64(m.t.len()) < m.shrinkFactor*float64(m.t.capacity()) {
+		m.detach()
+		m.t.compact()
 	}
-	m.buckets = newBuckets
 }
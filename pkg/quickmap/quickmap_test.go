@@ -3,6 +3,7 @@ package quickmap
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -41,20 +42,20 @@ func TestQuickMap(t *testing.T) {
 
 	// Test resize
 	t.Run("Resize", func(t *testing.T) {
-		initialCap := len(m.buckets)
+		initialCap := m.t.capacity()
 		for i := 0; i < 100; i++ {
 			m.Insert(strconv.Itoa(i), i)
 		}
-		if len(m.buckets) <= initialCap {
-			t.Errorf("Expected resize to occur, but capacity remained at %d", len(m.buckets))
+		if m.t.capacity() <= initialCap {
+			t.Errorf("Expected resize to occur, but capacity remained at %d", m.t.capacity())
 		}
 	})
 
 	// Test NewWithCapacity
 	t.Run("NewWithCapacity", func(t *testing.T) {
 		m := NewWithCapacity(100)
-		if len(m.buckets) < 100 {
-			t.Errorf("NewWithCapacity(100) created a map with capacity %d, expected at least 100", len(m.buckets))
+		if m.t.capacity() < 100 {
+			t.Errorf("NewWithCapacity(100) created a map with capacity %d, expected at least 100", m.t.capacity())
 		}
 	})
 
@@ -102,6 +103,288 @@ func TestQuickMap(t *testing.T) {
 	})
 }
 
+func TestQuickMapGeneric(t *testing.T) {
+	// Test integer keys with the default hasher
+	t.Run("Integer keys", func(t *testing.T) {
+		m := NewMap[int, string](nil)
+		for i := 0; i < 100; i++ {
+			m.Insert(i, strconv.Itoa(i))
+		}
+		if m.Size() != 100 {
+			t.Errorf("Size() = %d, expected 100", m.Size())
+		}
+		for i := 0; i < 100; i++ {
+			if value, exists := m.Get(i); !exists || value != strconv.Itoa(i) {
+				t.Errorf("Get(%d) = %q, %t; expected %q, true", i, value, exists, strconv.Itoa(i))
+			}
+		}
+	})
+
+	// Test struct keys with a custom hasher
+	t.Run("Custom hasher", func(t *testing.T) {
+		type point struct{ x, y int }
+		hasher := func(p point, seed uint64) uint64 {
+			return IntegerHasher(p.x, seed)*31 + IntegerHasher(p.y, seed)
+		}
+		m := NewMapWithCapacity[point, int](hasher, 4)
+		m.Insert(point{1, 2}, 3)
+		m.Insert(point{2, 1}, 3)
+		m.Delete(point{2, 1})
+		if value, exists := m.Get(point{1, 2}); !exists || value != 3 {
+			t.Errorf("Get(point{1, 2}) = %d, %t; expected 3, true", value, exists)
+		}
+		if _, exists := m.Get(point{2, 1}); exists {
+			t.Errorf("Get(point{2, 1}) returned true after deletion, expected false")
+		}
+	})
+
+	// Test named string keys
+	t.Run("Named string keys", func(t *testing.T) {
+		type userID string
+		m := NewMap[userID, int](StringHasher[userID])
+		m.Insert("alice", 1)
+		if value, exists := m.Get("alice"); !exists || value != 1 {
+			t.Errorf("Get(\"alice\") = %d, %t; expected 1, true", value, exists)
+		}
+	})
+
+	// Test DefaultHasher panics for unsupported key types
+	t.Run("DefaultHasher unsupported", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("NewMap with a struct key and nil hasher did not panic")
+			}
+		}()
+		NewMap[struct{ a int }, int](nil)
+	})
+}
+
+func TestQuickMapSeed(t *testing.T) {
+	bucketKeys := func(m *StringMap) []string {
+		var keys []string
+		m.ForEach(func(key string, value interface{}) {
+			keys = append(keys, key)
+		})
+		return keys
+	}
+
+	// Test that a pinned seed reproduces the same bucket layout
+	t.Run("WithSeed", func(t *testing.T) {
+		m1 := New(WithSeed(42))
+		m2 := New(WithSeed(42))
+		for i := 0; i < 100; i++ {
+			m1.Insert(strconv.Itoa(i), i)
+			m2.Insert(strconv.Itoa(i), i)
+		}
+		if fmt.Sprint(bucketKeys(m1)) != fmt.Sprint(bucketKeys(m2)) {
+			t.Errorf("Maps created WithSeed(42) have different bucket layouts")
+		}
+	})
+
+	// Test that every map draws its own random seed
+	t.Run("Random seed", func(t *testing.T) {
+		m1 := New()
+		m2 := New()
+		if m1.seed == m2.seed {
+			t.Errorf("Two maps created with New() share seed %d, expected different seeds", m1.seed)
+		}
+		m1.Insert("key", 1)
+		if value, exists := m1.Get("key"); !exists || value != 1 {
+			t.Errorf("Get(\"key\") = %v, %t; expected 1, true", value, exists)
+		}
+	})
+}
+
+func TestQuickMapHashFunc(t *testing.T) {
+	// Test every registered hash function
+	for _, name := range HasherNames() {
+		t.Run(name, func(t *testing.T) {
+			m := NewWithCapacity(8, WithHashFunc(name))
+			for i := 0; i < 1000; i++ {
+				m.Insert(strings.Repeat("k", i%40)+strconv.Itoa(i), i)
+			}
+			if m.Size() != 1000 {
+				t.Errorf("Size() = %d, expected 1000", m.Size())
+			}
+			for i := 0; i < 1000; i++ {
+				key := strings.Repeat("k", i%40) + strconv.Itoa(i)
+				if value, exists := m.Get(key); !exists || value != i {
+					t.Errorf("Get(%q) = %v, %t; expected %d, true", key, value, exists, i)
+				}
+			}
+		})
+	}
+
+	// Test that the selected hash function is the one used
+	t.Run("Selected", func(t *testing.T) {
+		m := New(WithHashFunc("fnv1"))
+		if m.hasher("key", 7) != FNV1Hasher("key", 7) {
+			t.Errorf("New(WithHashFunc(\"fnv1\")) does not hash with FNV1Hasher")
+		}
+		g := NewMap[string, int](nil, WithHashFunc("wyhash"))
+		if g.hasher("key", 7) != WyHasher("key", 7) {
+			t.Errorf("NewMap(nil, WithHashFunc(\"wyhash\")) does not hash with WyHasher")
+		}
+	})
+
+	// Test RegisterHasher
+	t.Run("RegisterHasher", func(t *testing.T) {
+		if _, ok := LookupHasher("test-constant"); !ok {
+			RegisterHasher("test-constant", func(key string, seed uint64) uint64 { return 0 })
+		}
+		if _, ok := LookupHasher("test-constant"); !ok {
+			t.Errorf("LookupHasher(\"test-constant\") returned false after RegisterHasher")
+		}
+		m := New(WithHashFunc("test-constant"))
+		m.InsertMany(map[string]interface{}{"a": 1, "b": 2, "c": 3})
+		if value, exists := m.Get("b"); !exists || value != 2 {
+			t.Errorf("Get(\"b\") = %v, %t; expected 2, true", value, exists)
+		}
+		defer func() {
+			if recover() == nil {
+				t.Errorf("RegisterHasher with a duplicate name did not panic")
+			}
+		}()
+		RegisterHasher("test-constant", FNV1Hasher[string])
+	})
+
+	// Test unknown names
+	t.Run("Unknown", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("WithHashFunc(\"nope\") did not panic")
+			}
+		}()
+		New(WithHashFunc("nope"))
+	})
+}
+
+var backends = []Backend{Chaining, Swiss, RobinHood}
+
+func TestQuickMapBackends(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.String(), func(t *testing.T) {
+			m := NewWithCapacity(4, WithBackend(backend))
+			for i := 0; i < 1000; i++ {
+				m.Insert(strconv.Itoa(i), i)
+			}
+			for i := 0; i < 1000; i += 3 {
+				m.Delete(strconv.Itoa(i))
+			}
+			m.InsertMany(map[string]interface{}{"zero": 0, "one": 1})
+			m.DeleteMany([]string{"2", "4", "missing"})
+
+			if m.Size() != 666 {
+				t.Errorf("Size() = %d, expected 666", m.Size())
+			}
+			if value, exists := m.Get("zero"); !exists || value != 0 {
+				t.Errorf("Get(\"zero\") = %v, %t; expected 0, true", value, exists)
+			}
+			if value, exists := m.Get("1"); !exists || value != 1 {
+				t.Errorf("Get(\"1\") = %v, %t; expected 1, true", value, exists)
+			}
+			for _, key := range []string{"2", "3", "4", "999"} {
+				if _, exists := m.Get(key); exists {
+					t.Errorf("Get(%q) returned true after deletion, expected false", key)
+				}
+			}
+			seen := 0
+			m.ForEach(func(key string, value interface{}) {
+				if v, exists := m.Get(key); !exists || v != value {
+					t.Errorf("ForEach visited %q = %v, but Get returned %v, %t", key, value, v, exists)
+				}
+				seen++
+			})
+			if seen != m.Size() {
+				t.Errorf("ForEach visited %d entries, expected %d", seen, m.Size())
+			}
+		})
+	}
+}
+
+func TestQuickMapShrink(t *testing.T) {
+	for _, backend := range backends {
+		// Test that deletions below the shrink factor compact the table
+		t.Run(backend.String()+"/WithShrinkFactor", func(t *testing.T) {
+			m := NewMap[int, int](nil, WithBackend(backend), WithShrinkFactor(0.1))
+			for i := 0; i < 10000; i++ {
+				m.Insert(i, i)
+			}
+			peak := m.t.capacity()
+			for i := 0; i < 9900; i++ {
+				m.Delete(i)
+			}
+			if m.t.capacity() >= peak/8 {
+				t.Errorf("Capacity = %d after deleting 99%% of entries, expected well below %d", m.t.capacity(), peak)
+			}
+			if m.Size() != 100 {
+				t.Errorf("Size() = %d, expected 100", m.Size())
+			}
+			for i := 9900; i < 10000; i++ {
+				if value, exists := m.Get(i); !exists || value != i {
+					t.Errorf("Get(%d) = %d, %t after shrinking; expected %d, true", i, value, exists, i)
+				}
+			}
+		})
+
+		// Test that DeleteMany shrinks once at the end
+		t.Run(backend.String()+"/DeleteMany", func(t *testing.T) {
+			m := NewMap[int, int](nil, WithBackend(backend), WithShrinkFactor(0.25))
+			keys := make([]int, 10000)
+			for i := range keys {
+				m.Insert(i, i)
+				keys[i] = i
+			}
+			peak := m.t.capacity()
+			m.DeleteMany(keys[:9000])
+			if m.t.capacity() >= peak {
+				t.Errorf("Capacity = %d after DeleteMany, expected below %d", m.t.capacity(), peak)
+			}
+			if m.Size() != 1000 {
+				t.Errorf("Size() = %d, expected 1000", m.Size())
+			}
+		})
+
+		// Test that maps never shrink by default, but Compact does
+		t.Run(backend.String()+"/Compact", func(t *testing.T) {
+			m := NewMap[int, int](nil, WithBackend(backend))
+			for i := 0; i < 10000; i++ {
+				m.Insert(i, i)
+			}
+			peak := m.t.capacity()
+			for i := 0; i < 9990; i++ {
+				m.Delete(i)
+			}
+			if m.t.capacity() != peak {
+				t.Errorf("Capacity changed from %d to %d without WithShrinkFactor", peak, m.t.capacity())
+			}
+			m.Compact()
+			if m.t.capacity() > 16 {
+				t.Errorf("Capacity = %d after Compact with 10 entries, expected at most 16", m.t.capacity())
+			}
+			for i := 9990; i < 10000; i++ {
+				if _, exists := m.Get(i); !exists {
+					t.Errorf("Get(%d) returned false after Compact, expected true", i)
+				}
+			}
+			m.Insert(-1, -1)
+			if m.Size() != 11 {
+				t.Errorf("Size() = %d after Compact and Insert, expected 11", m.Size())
+			}
+		})
+	}
+
+	// Test out-of-range factors
+	t.Run("Invalid factor", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("WithShrinkFactor(0.5) did not panic")
+			}
+		}()
+		WithShrinkFactor(0.5)
+	})
+}
+
 func BenchmarkQuickMap(b *testing.B) {
 	m := New()
 
@@ -165,3 +448,53 @@ func BenchmarkQuickMap(b *testing.B) {
         })
     }
 }
+
+func BenchmarkHashers(b *testing.B) {
+	for _, length := range []int{8, 64, 256} {
+		keys := make([]string, 1000)
+		for i := range keys {
+			key := strconv.Itoa(i)
+			keys[i] = strings.Repeat("x", length-len(key)) + key
+		}
+		for _, name := range HasherNames() {
+			b.Run(fmt.Sprintf("%s/%dB", name, length), func(b *testing.B) {
+				m := NewWithCapacity(len(keys), WithHashFunc(name))
+				for _, key := range keys {
+					m.Insert(key, nil)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.Get(keys[i%len(keys)])
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkBackends(b *testing.B) {
+	keys := make([]string, 100000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	for _, backend := range backends {
+		b.Run(backend.String()+"/Insert", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m := NewMap[string, int](nil, WithBackend(backend))
+				for j, key := range keys {
+					m.Insert(key, j)
+				}
+			}
+		})
+		b.Run(backend.String()+"/Get", func(b *testing.B) {
+			m := NewMap[string, int](nil, WithBackend(backend))
+			for j, key := range keys {
+				m.Insert(key, j)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Get(keys[i%len(keys)])
+			}
+		})
+	}
+}
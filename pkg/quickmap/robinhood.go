@@ -0,0 +1,231 @@
+package quickmap
+
+import "time"
+
+const (
+	robinMinCapacity = 8
+
+	// occupied is set on every stored hash so that 0 can mark an empty slot
+	occupied = 1 << 63
+)
+
+// robinTable is the RobinHood backend: an open-addressed table with linear
+// probing that keeps keys, hashes and values in flat arrays. An insert takes
+// the slot of any entry that sits closer to its home slot than the new entry
+// would, which keeps probe lengths short and even. Deletion shifts the
+// following run of entries back by one slot, so the table never holds
+// tombstones.
+type robinTable[K comparable, V any] struct {
+	hashes []uint64
+	keys   []K
+	values []V
+	size   int
+	mask   uint64
+
+	rehashStats
+}
+
+func newRobinTable[K comparable, V any](initialCapacity int) *robinTable[K, V] {
+	t := &robinTable[K, V]{}
+	t.init(robinCapacityFor(initialCapacity))
+	return t
+}
+
+// robinCapacityFor returns the power-of-two capacity that holds n entries
+// without exceeding the maximum load
+func robinCapacityFor(n int) int {
+	capacity := robinMinCapacity
+	for robinMaxLoad(capacity) < n {
+		capacity *= 2
+	}
+	return capacity
+}
+
+// robinMaxLoad returns the number of entries a table of the given capacity
+// holds before it grows, a load factor of 0.9
+func robinMaxLoad(capacity int) int {
+	return capacity * 9 / 10
+}
+
+func (t *robinTable[K, V]) init(capacity int) {
+	t.hashes = make([]uint64, capacity)
+	t.keys = make([]K, capacity)
+	t.values = make([]V, capacity)
+	t.size = 0
+	t.mask = uint64(capacity - 1)
+}
+
+// dist returns how far slot i is from the home slot of the hash stored in it
+func (t *robinTable[K, V]) dist(i uint64) uint64 {
+	return (i - t.hashes[i]) & t.mask
+}
+
+// find returns the slot holding key
+func (t *robinTable[K, V]) find(key K, h uint64) (uint64, bool) {
+	h |= occupied
+	i := h & t.mask
+	for d := uint64(0); ; d++ {
+		stored := t.hashes[i]
+		// An empty slot, or an entry closer to its home than we are to ours,
+		// means the key would have been placed before this point
+		if stored == 0 || t.dist(i) < d {
+			return 0, false
+		}
+		if stored == h && t.keys[i] == key {
+			return i, true
+		}
+		i = (i + 1) & t.mask
+	}
+}
+
+func (t *robinTable[K, V]) insert(key K, h uint64, value V) {
+	if i, ok := t.find(key, h); ok {
+		t.values[i] = value
+		return
+	}
+	if t.size >= robinMaxLoad(len(t.hashes)) {
+		t.resize(len(t.hashes) * 2)
+	}
+	t.insertNew(key, h|occupied, value)
+}
+
+// insertNew places an entry whose key is known to be absent
+func (t *robinTable[K, V]) insertNew(key K, h uint64, value V) {
+	i := h & t.mask
+	for d := uint64(0); ; d++ {
+		if t.hashes[i] == 0 {
+			t.hashes[i], t.keys[i], t.values[i] = h, key, value
+			t.size++
+			return
+		}
+		if existing := t.dist(i); existing < d {
+			// Take the slot from the richer entry and carry it forward
+			h, t.hashes[i] = t.hashes[i], h
+			key, t.keys[i] = t.keys[i], key
+			value, t.values[i] = t.values[i], value
+			d = existing
+		}
+		i = (i + 1) & t.mask
+	}
+}
+
+func (t *robinTable[K, V]) get(key K, h uint64) (V, bool) {
+	if i, ok := t.find(key, h); ok {
+		return t.values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+func (t *robinTable[K, V]) remove(key K, h uint64) {
+	if i, ok := t.find(key, h); ok {
+		t.removeAt(i)
+	}
+}
+
+// removeAt empties slot i
+func (t *robinTable[K, V]) removeAt(i uint64) {
+	// Shift the rest of the run back one slot, stopping at an empty slot or an
+	// entry already in its home slot
+	for {
+		next := (i + 1) & t.mask
+		if t.hashes[next] == 0 || t.dist(next) == 0 {
+			break
+		}
+		t.hashes[i], t.keys[i], t.values[i] = t.hashes[next], t.keys[next], t.values[next]
+		i = next
+	}
+
+	var zeroKey K
+	var zeroValue V
+	t.hashes[i], t.keys[i], t.values[i] = 0, zeroKey, zeroValue
+	t.size--
+}
+
+func (t *robinTable[K, V]) compute(key K, h uint64, f func(old V, exists bool) (V, bool)) (V, bool) {
+	var zero V
+	if i, ok := t.find(key, h); ok {
+		value, keep := f(t.values[i], true)
+		if !keep {
+			t.removeAt(i)
+			return zero, false
+		}
+		t.values[i] = value
+		return value, true
+	}
+
+	value, keep := f(zero, false)
+	if !keep {
+		return zero, false
+	}
+	if t.size >= robinMaxLoad(len(t.hashes)) {
+		t.resize(len(t.hashes) * 2)
+	}
+	t.insertNew(key, h|occupied, value)
+	return value, true
+}
+
+func (t *robinTable[K, V]) removeFunc(f func(key K, value V) bool) {
+	// Start just after an empty slot, which the load limit guarantees, so
+	// that backward shifts only ever move entries the scan has yet to reach.
+	// A slot is checked again after a removal shifts a new entry into it.
+	start := uint64(0)
+	for t.hashes[start] != 0 {
+		start++
+	}
+	for n := uint64(1); n <= t.mask; n++ {
+		i := (start + n) & t.mask
+		for t.hashes[i] != 0 && f(t.keys[i], t.values[i]) {
+			t.removeAt(i)
+		}
+	}
+}
+
+func (t *robinTable[K, V]) len() int {
+	return t.size
+}
+
+func (t *robinTable[K, V]) capacity() int {
+	return len(t.hashes)
+}
+
+func (t *robinTable[K, V]) forEach(f func(key K, value V) bool) {
+	for i, h := range t.hashes {
+		if h != 0 && !f(t.keys[i], t.values[i]) {
+			return
+		}
+	}
+}
+
+func (t *robinTable[K, V]) clone() table[K, V] {
+	c := *t
+	c.hashes = append([]uint64(nil), t.hashes...)
+	c.keys = append([]K(nil), t.keys...)
+	c.values = append([]V(nil), t.values...)
+	return &c
+}
+
+func (t *robinTable[K, V]) reserve(n int) {
+	if n > robinMaxLoad(len(t.hashes)) {
+		t.resize(robinCapacityFor(n))
+	}
+}
+
+func (t *robinTable[K, V]) compact() {
+	if capacity := robinCapacityFor(t.size); capacity < len(t.hashes) {
+		t.resize(capacity)
+	}
+}
+
+// resize moves every entry into a table of the given capacity. Stored hashes
+// are reused, so keys are not hashed again.
+func (t *robinTable[K, V]) resize(capacity int) {
+	defer t.rehashed(time.Now())
+	hashes, keys, values := t.hashes, t.keys, t.values
+	t.init(capacity)
+	for i, h := range hashes {
+		if h != 0 {
+			t.insertNew(keys[i], h, values[i])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package quickmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkRobinInvariant verifies that no entry is further from its home slot
+// than the entry before it allows, which is what makes early exits in find valid
+func checkRobinInvariant[K comparable, V any](t *testing.T, rt *robinTable[K, V]) {
+	t.Helper()
+	count := 0
+	for i := range rt.hashes {
+		if rt.hashes[i] == 0 {
+			continue
+		}
+		count++
+		prev := (uint64(i) - 1) & rt.mask
+		if d := rt.dist(uint64(i)); d > 0 && (rt.hashes[prev] == 0 || rt.dist(prev)+1 < d) {
+			t.Fatalf("Slot %d has probe distance %d after slot with distance %d", i, d, rt.dist(prev))
+		}
+	}
+	if count != rt.size {
+		t.Fatalf("Table holds %d entries, size is %d", count, rt.size)
+	}
+}
+
+func TestRobinHoodTable(t *testing.T) {
+	// Test that backward-shift deletion keeps every run contiguous
+	t.Run("Backward shift", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 1, WithBackend(RobinHood))
+		rt := m.t.(*robinTable[int, int])
+		for i := 0; i < 1000; i++ {
+			m.Insert(i, i)
+		}
+		checkRobinInvariant(t, rt)
+		for i := 0; i < 1000; i += 2 {
+			m.Delete(i)
+			checkRobinInvariant(t, rt)
+		}
+		for i := 0; i < 1000; i++ {
+			_, exists := m.Get(i)
+			if exists != (i%2 == 1) {
+				t.Errorf("Get(%d) returned %t, expected %t", i, exists, i%2 == 1)
+			}
+		}
+	})
+
+	// Test that a delete-heavy workload keeps the table at its size
+	t.Run("Churn", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 900, WithBackend(RobinHood))
+		rt := m.t.(*robinTable[int, int])
+		initialCap := rt.capacity()
+		for i := 0; i < 100000; i++ {
+			m.Insert(i, i)
+			if i >= 900 {
+				m.Delete(i - 900)
+			}
+		}
+		if rt.capacity() != initialCap {
+			t.Errorf("Capacity grew from %d to %d with at most 901 live entries", initialCap, rt.capacity())
+		}
+		checkRobinInvariant(t, rt)
+	})
+
+	// Test random operations against the built-in map
+	t.Run("Random operations", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		m := NewMapWithCapacity[int, int](nil, 1, WithBackend(RobinHood))
+		model := make(map[int]int)
+		for i := 0; i < 200000; i++ {
+			key := rng.Intn(5000)
+			switch rng.Intn(3) {
+			case 0:
+				m.Insert(key, i)
+				model[key] = i
+			case 1, 2:
+				m.Delete(key)
+				delete(model, key)
+			}
+		}
+		checkRobinInvariant(t, m.t.(*robinTable[int, int]))
+		if m.Size() != len(model) {
+			t.Errorf("Size() = %d, expected %d", m.Size(), len(model))
+		}
+		for key := 0; key < 5000; key++ {
+			value, exists := m.Get(key)
+			expected, expectedExists := model[key]
+			if exists != expectedExists || value != expected {
+				t.Errorf("Get(%d) = %d, %t; expected %d, %t", key, value, exists, expected, expectedExists)
+			}
+		}
+	})
+}
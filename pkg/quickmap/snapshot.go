@@ -0,0 +1,362 @@
+package quickmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/marpit19/goquickmap/internal/ioutil"
+)
+
+// A snapshot is laid out as
+//
+//	header:  magic "GQMS" | version byte | flags byte | entry count (uvarint) | header CRC
+//	entries: key length (uvarint) | key | value length (uvarint) | value
+//	trailer: CRC of everything before it
+//
+// CRCs are CRC-32C, stored little endian. The header has its own CRC so that
+// a damaged entry count is caught before the table is allocated for it. The
+// CRC is no defence against a crafted count, so the table is preallocated for
+// at most snapshotPrealloc entries and grows as more are read.
+const (
+	snapshotMagic   = "GQMS"
+	snapshotVersion = 1
+
+	// snapshotValues is set in the flags byte when entries carry values.
+	// Snapshots of sets store keys only.
+	snapshotValues = 1 << 0
+
+	// maxSnapshotField bounds the length of a single key or value, so that a
+	// corrupt length fails cleanly instead of allocating without limit
+	maxSnapshotField = 1 << 30
+
+	// snapshotChunk is the most that is read into a field's buffer at once
+	snapshotChunk = 64 << 10
+
+	// snapshotPrealloc bounds the entries ReadFrom allocates the table for
+	// before reading them
+	snapshotPrealloc = 1 << 16
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptSnapshot is returned, wrapped with details, by ReadFrom when a
+// snapshot is truncated, malformed or fails a checksum
+var ErrCorruptSnapshot = errors.New("quickmap: corrupt snapshot")
+
+// WriteTo writes a snapshot of the map to w in a compact, versioned binary
+// format and returns the number of bytes written. Keys and values are
+// encoded with the codecs set by WithKeyCodec and WithValueCodec, or
+// DefaultCodec, each prefixed with its length; a map of struct{} values, like
+// the one behind a QuickSet, stores keys only. The snapshot records the
+// number of entries, so that ReadFrom can size the table once, and ends with
+// a CRC-32C checksum.
+func (m *QuickMap[K, V]) WriteTo(w io.Writer) (int64, error) {
+	values := !isSet[V]()
+	count := 0
+	if m.t != nil {
+		count = m.t.len()
+	}
+	s := newSnapshotWriter(w)
+	s.header(count, values)
+
+	written := 0
+	if m.t != nil {
+		var err error
+		m.t.forEach(func(key K, value V) bool {
+			if s.buf, err = m.keyCodec.Append(s.buf[:0], key); err != nil {
+				return false
+			}
+			s.field(s.buf)
+			if values {
+				if s.buf, err = m.valueCodec.Append(s.buf[:0], value); err != nil {
+					return false
+				}
+				s.field(s.buf)
+			}
+			written++
+			return s.err == nil
+		})
+		if err != nil {
+			return s.cw.N, err
+		}
+	}
+	if s.err == nil && written != count {
+		return s.cw.N, fmt.Errorf("quickmap: map holds %d entries, but its size is %d", written, count)
+	}
+	s.trailer()
+	return s.cw.N, s.err
+}
+
+// ReadFrom replaces the contents of the map with a snapshot written by
+// WriteTo and returns the number of bytes read. The table is allocated once,
+// at the size the snapshot records, with the map's backend and hasher; past
+// a bound, it is allocated smaller and grows as entries are read. The
+// map is left unchanged if the snapshot is corrupt, in which case the error
+// wraps ErrCorruptSnapshot, or if it holds a set where a map is expected or
+// the other way around. A zero QuickMap is first initialised as if by NewMap
+// with no hasher and no options.
+//
+// Unless r is a *bufio.Reader, ReadFrom buffers its input and may read past
+// the end of the snapshot.
+func (m *QuickMap[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	if m.t == nil {
+		o := newOptions(nil)
+		m.init(resolveHasher[K](nil, o), defaultInitialSize, o)
+	}
+	if m.metrics != nil {
+		defer m.record(m.state(), false)
+	}
+	values := !isSet[V]()
+	s := newSnapshotReader(r)
+	count, err := s.header(values)
+	if err != nil {
+		return s.n, err
+	}
+
+	t := m.emptyTable(min(count, snapshotPrealloc))
+	var value V
+	for i := 0; i < count; i++ {
+		data, err := s.field()
+		if err != nil {
+			return s.n, err
+		}
+		key, err := m.keyCodec.Decode(data)
+		if err != nil {
+			return s.n, fmt.Errorf("quickmap: decoding snapshot key: %w", err)
+		}
+		if values {
+			if data, err = s.field(); err != nil {
+				return s.n, err
+			}
+			if value, err = m.valueCodec.Decode(data); err != nil {
+				return s.n, fmt.Errorf("quickmap: decoding snapshot value: %w", err)
+			}
+		}
+		t.insert(key, m.hash(key), value)
+	}
+	if err := s.trailer(); err != nil {
+		return s.n, err
+	}
+	if t.len() != count {
+		return s.n, fmt.Errorf("%w: duplicate keys", ErrCorruptSnapshot)
+	}
+
+	// Iterations in progress keep walking the old table, as after detach
+	capacity := m.t.capacity()
+	m.t = t
+	m.walkers = 0
+	m.checkInvariants()
+	if m.listeners != nil {
+		m.emit(Event[K, V]{Kind: Cleared})
+		t.forEach(func(key K, value V) bool {
+			m.emit(Event[K, V]{Kind: Inserted, Key: key, Value: value})
+			return true
+		})
+		m.notifyResize(capacity)
+	}
+	return s.n, nil
+}
+
+// emptyTable returns an empty table of the same kind and configuration as
+// m.t that holds n entries without resizing
+func (m *QuickMap[K, V]) emptyTable(n int) table[K, V] {
+	switch t := m.t.(type) {
+	case *swissTable[K, V]:
+		return newSwissTable[K, V](n, m.hash)
+	case *robinTable[K, V]:
+		return newRobinTable[K, V](n)
+	case *linkedTable[K, V]:
+		return newLinkedTable[K, V](minChainCapacity(n), m.hash)
+	case *chainTable[K, V]:
+		return newChainTable[K, V](minChainCapacity(n), m.hash, t.resizeStep)
+	}
+	panic(fmt.Sprintf("quickmap: unknown table %T", m.t))
+}
+
+// snapshotWriter writes the parts of a snapshot, keeping a running CRC. The
+// first error stops all further writes and is kept in err.
+type snapshotWriter struct {
+	cw  *ioutil.CountingWriter
+	w   *bufio.Writer
+	crc uint32
+	err error
+	// buf is scratch space for encoding keys and values
+	buf     []byte
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func newSnapshotWriter(w io.Writer) *snapshotWriter {
+	cw := &ioutil.CountingWriter{W: w}
+	return &snapshotWriter{cw: cw, w: bufio.NewWriter(cw)}
+}
+
+func (s *snapshotWriter) write(p []byte) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = s.w.Write(p)
+	s.crc = crc32.Update(s.crc, crcTable, p)
+}
+
+func (s *snapshotWriter) uvarint(x uint64) {
+	s.write(binary.AppendUvarint(s.scratch[:0], x))
+}
+
+func (s *snapshotWriter) checksum() {
+	s.write(binary.LittleEndian.AppendUint32(s.scratch[:0], s.crc))
+}
+
+func (s *snapshotWriter) header(count int, values bool) {
+	var flags byte
+	if values {
+		flags |= snapshotValues
+	}
+	s.write(append([]byte(snapshotMagic), snapshotVersion, flags))
+	s.uvarint(uint64(count))
+	s.checksum()
+}
+
+func (s *snapshotWriter) field(data []byte) {
+	s.uvarint(uint64(len(data)))
+	s.write(data)
+}
+
+func (s *snapshotWriter) trailer() {
+	s.checksum()
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+}
+
+// snapshotReader reads the parts of a snapshot, keeping a running CRC and a
+// count of the bytes consumed
+type snapshotReader struct {
+	r   *bufio.Reader
+	n   int64
+	crc uint32
+	buf []byte
+	one [1]byte
+}
+
+func newSnapshotReader(r io.Reader) *snapshotReader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &snapshotReader{r: br}
+}
+
+// read returns the next n bytes, which are only valid until the next call.
+// The buffer grows only as data arrives, so a corrupt length cannot make it
+// allocate far more than the input holds.
+func (s *snapshotReader) read(n int) ([]byte, error) {
+	s.buf = s.buf[:0]
+	for len(s.buf) < n {
+		chunk := min(n-len(s.buf), snapshotChunk)
+		s.buf = slices.Grow(s.buf, chunk)
+		p := s.buf[len(s.buf) : len(s.buf)+chunk]
+		k, err := io.ReadFull(s.r, p)
+		s.n += int64(k)
+		if err != nil {
+			return nil, truncated(err)
+		}
+		s.buf = s.buf[:len(s.buf)+chunk]
+	}
+	s.crc = crc32.Update(s.crc, crcTable, s.buf)
+	return s.buf, nil
+}
+
+func (s *snapshotReader) uvarint() (uint64, error) {
+	var x uint64
+	for shift := 0; shift < 64; shift += 7 {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return 0, truncated(err)
+		}
+		s.n++
+		s.one[0] = b
+		s.crc = crc32.Update(s.crc, crcTable, s.one[:])
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: varint overflows 64 bits", ErrCorruptSnapshot)
+}
+
+// checksum reads a stored CRC and compares it with the CRC of everything
+// read before it
+func (s *snapshotReader) checksum(what string) error {
+	expected := s.crc
+	p, err := s.read(4)
+	if err != nil {
+		return err
+	}
+	if binary.LittleEndian.Uint32(p) != expected {
+		return fmt.Errorf("%w: %s checksum mismatch", ErrCorruptSnapshot, what)
+	}
+	return nil
+}
+
+// header reads the header and returns the entry count. values says whether
+// the caller expects entries to carry values.
+func (s *snapshotReader) header(values bool) (int, error) {
+	p, err := s.read(len(snapshotMagic) + 2)
+	if err != nil {
+		return 0, err
+	}
+	if string(p[:len(snapshotMagic)]) != snapshotMagic {
+		return 0, fmt.Errorf("%w: not a quickmap snapshot", ErrCorruptSnapshot)
+	}
+	version, flags := p[len(snapshotMagic)], p[len(snapshotMagic)+1]
+	count, err := s.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if err := s.checksum("header"); err != nil {
+		return 0, err
+	}
+	if version != snapshotVersion {
+		return 0, fmt.Errorf("quickmap: unsupported snapshot version %d", version)
+	}
+	if count > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: entry count %d is too large", ErrCorruptSnapshot, count)
+	}
+	switch hasValues := flags&snapshotValues != 0; {
+	case hasValues && !values:
+		return 0, errors.New("quickmap: snapshot holds a map, expected a set")
+	case !hasValues && values:
+		return 0, errors.New("quickmap: snapshot holds a set, expected a map")
+	}
+	return int(count), nil
+}
+
+// field reads a length-prefixed key or value
+func (s *snapshotReader) field() ([]byte, error) {
+	n, err := s.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > maxSnapshotField {
+		return nil, fmt.Errorf("%w: field length %d is too large", ErrCorruptSnapshot, n)
+	}
+	return s.read(int(n))
+}
+
+func (s *snapshotReader) trailer() error {
+	return s.checksum("snapshot")
+}
+
+// truncated reports an early end of input as corruption
+func truncated(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: unexpected end of data", ErrCorruptSnapshot)
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package quickmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	// Test a round trip on every backend
+	for _, backend := range []Backend{Chaining, Swiss, RobinHood} {
+		t.Run(backend.String(), func(t *testing.T) {
+			m := NewMap[string, int](nil, WithBackend(backend))
+			for i := 0; i < 10000; i++ {
+				m.Insert(strconv.Itoa(i), i)
+			}
+			var buf bytes.Buffer
+			n, err := m.WriteTo(&buf)
+			if err != nil || n != int64(buf.Len()) {
+				t.Fatalf("WriteTo = %d, %v; expected %d, nil", n, err, buf.Len())
+			}
+			loaded := NewMap[string, int](nil, WithBackend(backend))
+			loaded.Insert("stale", -1)
+			n, err = loaded.ReadFrom(&buf)
+			if err != nil || n != int64(buf.Cap()-buf.Available()) {
+				t.Fatalf("ReadFrom = %d, %v; expected all bytes, nil", n, err)
+			}
+			if loaded.Size() != 10000 {
+				t.Errorf("Size() = %d after ReadFrom, expected 10000", loaded.Size())
+			}
+			if _, exists := loaded.Get("stale"); exists {
+				t.Errorf("Get(\"stale\") returned true, expected ReadFrom to replace the contents")
+			}
+			if value, exists := loaded.Get("4242"); !exists || value != 4242 {
+				t.Errorf("Get(\"4242\") = %d, %t; expected 4242, true", value, exists)
+			}
+			if capacity := loaded.t.capacity(); capacity != m.emptyTable(10000).capacity() {
+				t.Errorf("capacity() = %d after ReadFrom, expected the table sized once for 10000 entries", capacity)
+			}
+		})
+	}
+
+	// Test that insertion order survives, and that the table keeps its options
+	t.Run("Linked", func(t *testing.T) {
+		m := NewLinkedMap[int, string](nil)
+		for _, key := range []int{5, -3, 9} {
+			m.Insert(key, strconv.Itoa(key))
+		}
+		var buf bytes.Buffer
+		if _, err := m.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo returned %v, expected nil", err)
+		}
+		loaded := NewLinkedMap[int, string](nil)
+		if _, err := loaded.ReadFrom(&buf); err != nil {
+			t.Fatalf("ReadFrom returned %v, expected nil", err)
+		}
+		if key, value, _ := loaded.First(); key != 5 || value != "5" {
+			t.Errorf("First() = %d, %q; expected 5, \"5\"", key, value)
+		}
+		if key, _, _ := loaded.Last(); key != 9 {
+			t.Errorf("Last() = %d, expected 9", key)
+		}
+	})
+
+	// Test loading into a zero map and reading data that follows the snapshot
+	t.Run("Zero map", func(t *testing.T) {
+		m := NewMap[uint16, bool](nil)
+		m.Insert(7, true)
+		var buf bytes.Buffer
+		m.WriteTo(&buf)
+		buf.WriteString("tail")
+		var loaded QuickMap[uint16, bool]
+		r := bufio.NewReader(&buf)
+		if _, err := loaded.ReadFrom(r); err != nil {
+			t.Fatalf("ReadFrom returned %v, expected nil", err)
+		}
+		if value, exists := loaded.Get(7); !exists || !value {
+			t.Errorf("Get(7) = %t, %t; expected true, true", value, exists)
+		}
+		if tail, _ := io.ReadAll(r); string(tail) != "tail" {
+			t.Errorf("data after the snapshot = %q, expected \"tail\"", tail)
+		}
+	})
+
+	// Test that corruption is detected and leaves the map unchanged
+	t.Run("Corruption", func(t *testing.T) {
+		m := NewMap[string, string](nil)
+		for i := 0; i < 100; i++ {
+			m.Insert(strconv.Itoa(i), strings.Repeat("x", i))
+		}
+		var buf bytes.Buffer
+		m.WriteTo(&buf)
+		snapshot := buf.Bytes()
+
+		for _, offset := range []int{0, 7, len(snapshot) / 2, len(snapshot) - 1} {
+			corrupt := bytes.Clone(snapshot)
+			corrupt[offset] ^= 0x40
+			loaded := NewMap[string, string](nil)
+			loaded.Insert("kept", "")
+			if _, err := loaded.ReadFrom(bytes.NewReader(corrupt)); !errors.Is(err, ErrCorruptSnapshot) {
+				t.Errorf("ReadFrom with byte %d flipped returned %v, expected ErrCorruptSnapshot", offset, err)
+			}
+			if _, exists := loaded.Get("kept"); !exists || loaded.Size() != 1 {
+				t.Errorf("ReadFrom changed the map after failing on byte %d", offset)
+			}
+		}
+		for _, length := range []int{0, 5, len(snapshot) - 4} {
+			_, err := NewMap[string, string](nil).ReadFrom(bytes.NewReader(snapshot[:length]))
+			if !errors.Is(err, ErrCorruptSnapshot) {
+				t.Errorf("ReadFrom of %d bytes returned %v, expected ErrCorruptSnapshot", length, err)
+			}
+		}
+	})
+
+	// Test that a header claiming a huge entry count, with a valid CRC but no
+	// entries, fails without allocating a table for the count
+	t.Run("Huge count", func(t *testing.T) {
+		header := append([]byte(snapshotMagic), snapshotVersion, snapshotValues)
+		header = binary.AppendUvarint(header, math.MaxInt32)
+		header = binary.LittleEndian.AppendUint32(header, crc32.Checksum(header, crcTable))
+		for _, backend := range backends {
+			m := NewMap[int, int](nil, WithBackend(backend))
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err := m.ReadFrom(bytes.NewReader(header))
+			runtime.ReadMemStats(&after)
+			if !errors.Is(err, ErrCorruptSnapshot) {
+				t.Errorf("%s: ReadFrom of a header alone returned %v, expected ErrCorruptSnapshot", backend, err)
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+				t.Errorf("%s: ReadFrom allocated %d bytes for a header alone, expected a bounded table", backend, allocated)
+			}
+		}
+	})
+
+	// Test that sets and maps cannot be mixed up
+	t.Run("Kind mismatch", func(t *testing.T) {
+		set := NewMap[string, struct{}](nil)
+		set.Insert("a", struct{}{})
+		var buf bytes.Buffer
+		set.WriteTo(&buf)
+		if _, err := NewMap[string, string](nil).ReadFrom(&buf); err == nil || errors.Is(err, ErrCorruptSnapshot) {
+			t.Errorf("ReadFrom of a set into a map returned %v, expected a mismatch error", err)
+		}
+	})
+
+	// Test custom codecs and the panic for a mismatched codec
+	t.Run("Codec", func(t *testing.T) {
+		m := NewMap[string, []int](nil, WithValueCodec[[]int](lengthCodec{}))
+		m.Insert("three", []int{1, 2, 3})
+		var buf bytes.Buffer
+		m.WriteTo(&buf)
+		loaded := NewMap[string, []int](nil, WithValueCodec[[]int](lengthCodec{}))
+		if _, err := loaded.ReadFrom(&buf); err != nil {
+			t.Fatalf("ReadFrom returned %v, expected nil", err)
+		}
+		if value, _ := loaded.Get("three"); len(value) != 3 {
+			t.Errorf("Get(\"three\") = %v, expected a slice of length 3", value)
+		}
+
+		defer func() {
+			if recover() == nil {
+				t.Errorf("NewMap with a codec for the wrong type did not panic")
+			}
+		}()
+		NewMap[string, string](nil, WithValueCodec[[]int](lengthCodec{}))
+	})
+}
+
+// lengthCodec stores only the length of a slice
+type lengthCodec struct{}
+
+func (lengthCodec) Append(buf []byte, value []int) ([]byte, error) {
+	return strconv.AppendInt(buf, int64(len(value)), 10), nil
+}
+
+func (lengthCodec) Decode(data []byte) ([]int, error) {
+	n, err := strconv.Atoi(string(data))
+	return make([]int, n), err
+}
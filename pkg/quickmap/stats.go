@@ -0,0 +1,164 @@
+package quickmap
+
+import "time"
+
+// Stats describes the layout of a map's table, for tuning its initial
+// capacity and spotting keys that hash badly. The Swiss and RobinHood
+// backends have no chains: for them a bucket is a slot, and an entry's chain
+// length is the number of groups (Swiss) or slots (RobinHood) a lookup of it
+// probes.
+type Stats struct {
+	Backend      Backend
+	Size         int     // entries in the map
+	Buckets      int     // buckets, or slots
+	LoadFactor   float64 // Size divided by Buckets
+	EmptyBuckets int     // buckets holding no entry, including Swiss tombstones
+	MaxChain     int     // longest chain
+	MeanChain    float64 // mean length of the non-empty chains, or of the probes
+	// ChainLengths[n] counts the buckets whose chain holds n entries. For the
+	// open-addressed backends, ChainLengths[0] counts the empty slots and
+	// ChainLengths[n] the entries found after probing n groups or slots.
+	ChainLengths []int
+	Collisions   int           // entries not stored first in their bucket, or not at their home slot
+	Resizes      int           // times the table has been rebuilt, growing, shrinking or clearing tombstones
+	RehashTime   time.Duration // total time spent rebuilding the table
+}
+
+// Stats walks the map's table and returns its statistics. It takes time in
+// proportion to the capacity, and completes any incremental resize.
+func (m *QuickMap[K, V]) Stats() Stats {
+	var s Stats
+	if m.t == nil {
+		return s
+	}
+	s = m.t.stats()
+	switch m.t.(type) {
+	case *swissTable[K, V]:
+		s.Backend = Swiss
+	case *robinTable[K, V]:
+		s.Backend = RobinHood
+	}
+	s.Size = m.t.len()
+	if s.Buckets > 0 {
+		s.LoadFactor = float64(s.Size) / float64(s.Buckets)
+	}
+	return s
+}
+
+// rehashStats counts the rebuilds of a table and the time they take
+type rehashStats struct {
+	resizes    int
+	rehashTime time.Duration
+}
+
+// rehashed records a rebuild that began at start. Tables call it deferred at
+// the top of each rebuild.
+func (r *rehashStats) rehashed(start time.Time) {
+	r.resizes++
+	r.rehashTime += time.Since(start)
+}
+
+func (r *rehashStats) rebuilds() int {
+	return r.resizes
+}
+
+// histogram accumulates chain lengths into a Stats
+type histogram struct {
+	s     Stats
+	total int
+	// chains counts the lengths added, so that MeanChain is over chains, not buckets
+	chains int
+}
+
+func (h *histogram) add(length int) {
+	for len(h.s.ChainLengths) <= length {
+		h.s.ChainLengths = append(h.s.ChainLengths, 0)
+	}
+	h.s.ChainLengths[length]++
+	if length == 0 {
+		h.s.EmptyBuckets++
+		return
+	}
+	h.s.MaxChain = max(h.s.MaxChain, length)
+	h.total += length
+	h.chains++
+}
+
+func (h *histogram) stats(buckets int, r rehashStats) Stats {
+	h.s.Buckets = buckets
+	if h.chains > 0 {
+		h.s.MeanChain = float64(h.total) / float64(h.chains)
+	}
+	h.s.Resizes = r.resizes
+	h.s.RehashTime = r.rehashTime
+	return h.s
+}
+
+func (t *chainTable[K, V]) stats() Stats {
+	t.finishResize()
+	var h histogram
+	for _, bucket := range t.buckets {
+		length := 0
+		for n := bucket; n != nil; n = n.next {
+			length++
+		}
+		if length > 1 {
+			h.s.Collisions += length - 1
+		}
+		h.add(length)
+	}
+	return h.stats(len(t.buckets), t.rehashStats)
+}
+
+func (t *linkedTable[K, V]) stats() Stats {
+	var h histogram
+	for _, bucket := range t.buckets {
+		length := 0
+		for n := bucket; n != nil; n = n.next {
+			length++
+		}
+		if length > 1 {
+			h.s.Collisions += length - 1
+		}
+		h.add(length)
+	}
+	return h.stats(len(t.buckets), t.rehashStats)
+}
+
+func (t *swissTable[K, V]) stats() Stats {
+	var h histogram
+	mask := uint64(len(t.ctrl) - 1)
+	for s := range t.slots {
+		if t.ctrlAt(uint64(s))&0x80 != 0 {
+			h.add(0)
+			continue
+		}
+		// Follow the key's probe sequence to the group that holds it
+		g, length := h1(t.hash(t.slots[s].key))&mask, 1
+		for i := uint64(1); g != uint64(s)/groupSize; i++ {
+			g = (g + i) & mask
+			length++
+		}
+		if length > 1 {
+			h.s.Collisions++
+		}
+		h.add(length)
+	}
+	return h.stats(len(t.slots), t.rehashStats)
+}
+
+func (t *robinTable[K, V]) stats() Stats {
+	var h histogram
+	for i := range t.hashes {
+		if t.hashes[i] == 0 {
+			h.add(0)
+			continue
+		}
+		length := int(t.dist(uint64(i))) + 1
+		if length > 1 {
+			h.s.Collisions++
+		}
+		h.add(length)
+	}
+	return h.stats(len(t.hashes), t.rehashStats)
+}
@@ -0,0 +1,99 @@
+package quickmap
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	// Test that the statistics agree with one another on every backend
+	t.Run("Backends", func(t *testing.T) {
+		for _, backend := range []Backend{Chaining, Swiss, RobinHood} {
+			t.Run(backend.String(), func(t *testing.T) {
+				m := NewMapWithCapacity[int, int](nil, 1, WithBackend(backend))
+				for i := 0; i < 1000; i++ {
+					m.Insert(i, i)
+				}
+				s := m.Stats()
+				if s.Backend != backend || s.Size != 1000 {
+					t.Errorf("Stats() = %v with Size %d, expected %v and 1000", s.Backend, s.Size, backend)
+				}
+				if s.LoadFactor != float64(s.Size)/float64(s.Buckets) {
+					t.Errorf("LoadFactor = %f, expected %d/%d", s.LoadFactor, s.Size, s.Buckets)
+				}
+				if s.Resizes == 0 {
+					t.Errorf("Resizes = 0 after growing from capacity 1, expected more")
+				}
+				checkHistogram(t, s)
+			})
+		}
+	})
+
+	// Test the chain statistics of a hasher that sends keys to two buckets
+	t.Run("Chains", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](func(key int, _ uint64) uint64 { return uint64(key % 2 * 5) }, 16)
+		for i := 0; i < 10; i++ {
+			m.Insert(i, i)
+		}
+		s := m.Stats()
+		if s.Buckets != 16 || s.EmptyBuckets != 14 || s.MaxChain != 5 || s.MeanChain != 5 {
+			t.Errorf("Stats() = %d buckets, %d empty, chains of %d and %.1f; expected 16, 14, 5 and 5", s.Buckets, s.EmptyBuckets, s.MaxChain, s.MeanChain)
+		}
+		if s.Collisions != 8 || len(s.ChainLengths) != 6 || s.ChainLengths[5] != 2 {
+			t.Errorf("Collisions = %d with ChainLengths %v, expected 8 and two chains of 5", s.Collisions, s.ChainLengths)
+		}
+		if s.Resizes != 0 {
+			t.Errorf("Resizes = %d, expected 0", s.Resizes)
+		}
+	})
+
+	// Test that Stats completes an incremental resize and counts compaction
+	t.Run("Incremental and compact", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](nil, 16, WithIncrementalResize(1))
+		for i := 0; i < 13; i++ {
+			m.Insert(i, i)
+		}
+		if s := m.Stats(); s.Buckets != 32 || s.Size != 13 || m.t.(*chainTable[int, int]).old != nil {
+			t.Errorf("Stats() = %d buckets for %d entries during migration, expected 32 and 13 with the migration done", s.Buckets, s.Size)
+		}
+		checkHistogram(t, m.Stats())
+		m.DeleteFunc(func(key, value int) bool { return key > 2 })
+		m.Compact()
+		if s := m.Stats(); s.Resizes != 2 {
+			t.Errorf("Resizes = %d after growing and compacting, expected 2", s.Resizes)
+		}
+	})
+
+	// Test a linked map and a zero map
+	t.Run("Linked and zero", func(t *testing.T) {
+		m := NewLinkedMap[string, int](nil)
+		m.Insert("a", 1)
+		checkHistogram(t, m.Stats())
+		var zero QuickMap[string, int]
+		if s := zero.Stats(); s.Size != 0 || s.Buckets != 0 {
+			t.Errorf("Stats() of a zero map = %+v, expected zero", s)
+		}
+	})
+}
+
+// checkHistogram checks that the chain histogram accounts for every bucket
+// and matches the other chain statistics
+func checkHistogram(t *testing.T, s Stats) {
+	t.Helper()
+	buckets, entries := 0, 0
+	for length, count := range s.ChainLengths {
+		buckets += count
+		if length > 0 {
+			entries += count
+		}
+	}
+	if s.Backend == Chaining {
+		// One histogram entry per bucket
+		if buckets != s.Buckets {
+			t.Errorf("ChainLengths %v counts %d buckets, expected %d", s.ChainLengths, buckets, s.Buckets)
+		}
+	} else if entries != s.Size || buckets != s.Buckets {
+		// One histogram entry per slot, empty or full
+		t.Errorf("ChainLengths %v counts %d slots and %d entries, expected %d and %d", s.ChainLengths, buckets, entries, s.Buckets, s.Size)
+	}
+	if s.EmptyBuckets != s.ChainLengths[0] || len(s.ChainLengths) != s.MaxChain+1 {
+		t.Errorf("EmptyBuckets = %d with MaxChain %d, expected ChainLengths %v to match", s.EmptyBuckets, s.MaxChain, s.ChainLengths)
+	}
+}
@@ -0,0 +1,348 @@
+package quickmap
+
+import (
+	"math/bits"
+	"time"
+)
+
+const (
+	groupSize = 8
+
+	// Control bytes: a full slot stores the low seven bits of its hash (h2),
+	// so the high bit marks the two special states
+	ctrlEmpty   = 0x80
+	ctrlDeleted = 0xfe
+
+	lsbs = 0x0101010101010101
+	msbs = 0x8080808080808080
+)
+
+type swissSlot[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// swissTable is the Swiss backend: an open-addressed table split into groups
+// of eight slots. Each group has a word of control bytes that lets a probe
+// compare a key's h2 against all eight slots at once, so most lookups touch a
+// single group and compare at most one key.
+type swissTable[K comparable, V any] struct {
+	ctrl  []uint64 // one word of control bytes per group
+	slots []swissSlot[K, V]
+	size  int
+	// deleted counts tombstones, which keep probe sequences intact after a removal
+	deleted int
+	// growthLeft is the number of empty slots that can still be filled before
+	// the table exceeds its 7/8 maximum load and has to be rehashed
+	growthLeft int
+	hash       func(K) uint64
+
+	rehashStats
+}
+
+func newSwissTable[K comparable, V any](initialCapacity int, hash func(K) uint64) *swissTable[K, V] {
+	t := &swissTable[K, V]{hash: hash}
+	t.init(groupsFor(initialCapacity))
+	return t
+}
+
+// groupsFor returns the power-of-two number of groups that holds n entries
+// without exceeding the maximum load
+func groupsFor(n int) int {
+	slots := (n*8 + 6) / 7
+	groups := 1
+	for groups*groupSize < slots {
+		groups *= 2
+	}
+	return groups
+}
+
+func (t *swissTable[K, V]) init(groups int) {
+	t.ctrl = make([]uint64, groups)
+	for i := range t.ctrl {
+		t.ctrl[i] = lsbs * ctrlEmpty
+	}
+	t.slots = make([]swissSlot[K, V], groups*groupSize)
+	t.size = 0
+	t.deleted = 0
+	t.growthLeft = len(t.slots) * 7 / 8
+}
+
+func h1(h uint64) uint64 {
+	return h >> 7
+}
+
+func h2(h uint64) uint8 {
+	return uint8(h & 0x7f)
+}
+
+// matchH2 returns the high bit of every byte in w equal to h. It can report
+// false positives, but only for full slots, so callers still compare keys.
+func matchH2(w uint64, h uint8) uint64 {
+	x := w ^ (lsbs * uint64(h))
+	return (x - lsbs) &^ x & msbs
+}
+
+// matchEmpty returns the high bit of every empty control byte in w
+func matchEmpty(w uint64) uint64 {
+	return w &^ (w << 6) & msbs
+}
+
+// matchEmptyOrDeleted returns the high bit of every control byte in w that
+// is not full
+func matchEmptyOrDeleted(w uint64) uint64 {
+	return w & msbs
+}
+
+// matchFull returns the high bit of every full control byte in w
+func matchFull(w uint64) uint64 {
+	return ^w & msbs
+}
+
+// firstSlot returns the index within its group of the lowest byte set in mask
+func firstSlot(mask uint64) uint64 {
+	return uint64(bits.TrailingZeros64(mask)) / 8
+}
+
+func (t *swissTable[K, V]) ctrlAt(s uint64) uint8 {
+	return uint8(t.ctrl[s/groupSize] >> (8 * (s % groupSize)))
+}
+
+func (t *swissTable[K, V]) setCtrl(s uint64, c uint8) {
+	shift := 8 * (s % groupSize)
+	w := &t.ctrl[s/groupSize]
+	*w = *w&^(0xff<<shift) | uint64(c)<<shift
+}
+
+// find returns the slot holding key
+func (t *swissTable[K, V]) find(key K, h uint64) (uint64, bool) {
+	mask := uint64(len(t.ctrl) - 1)
+	g := h1(h) & mask
+	for i := uint64(1); ; i++ {
+		w := t.ctrl[g]
+		for m := matchH2(w, h2(h)); m != 0; m &= m - 1 {
+			s := g*groupSize + firstSlot(m)
+			if t.slots[s].key == key {
+				return s, true
+			}
+		}
+		if matchEmpty(w) != 0 {
+			return 0, false
+		}
+		g = (g + i) & mask
+	}
+}
+
+// findSlot returns the first empty or deleted slot on the probe sequence for h
+func (t *swissTable[K, V]) findSlot(h uint64) uint64 {
+	mask := uint64(len(t.ctrl) - 1)
+	g := h1(h) & mask
+	for i := uint64(1); ; i++ {
+		if m := matchEmptyOrDeleted(t.ctrl[g]); m != 0 {
+			return g*groupSize + firstSlot(m)
+		}
+		g = (g + i) & mask
+	}
+}
+
+func (t *swissTable[K, V]) insert(key K, h uint64, value V) {
+	if s, ok := t.find(key, h); ok {
+		t.slots[s].value = value
+		return
+	}
+
+	s := t.findSlot(h)
+	if t.growthLeft == 0 && t.ctrlAt(s) == ctrlEmpty {
+		t.rehash()
+		s = t.findSlot(h)
+	}
+	t.set(s, key, h, value)
+}
+
+// set stores a new entry in the empty or deleted slot s
+func (t *swissTable[K, V]) set(s uint64, key K, h uint64, value V) {
+	if t.ctrlAt(s) == ctrlDeleted {
+		t.deleted--
+	} else {
+		t.growthLeft--
+	}
+	t.setCtrl(s, h2(h))
+	t.slots[s] = swissSlot[K, V]{key: key, value: value}
+	t.size++
+}
+
+func (t *swissTable[K, V]) get(key K, h uint64) (V, bool) {
+	if s, ok := t.find(key, h); ok {
+		return t.slots[s].value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (t *swissTable[K, V]) remove(key K, h uint64) {
+	if s, ok := t.find(key, h); ok {
+		t.removeSlot(s)
+	}
+}
+
+// removeSlot empties the full slot s
+func (t *swissTable[K, V]) removeSlot(s uint64) {
+	// A group that still has an empty slot has never been probed past, so the
+	// slot can become empty again; otherwise it must stay as a tombstone.
+	if matchEmpty(t.ctrl[s/groupSize]) != 0 {
+		t.setCtrl(s, ctrlEmpty)
+		t.growthLeft++
+	} else {
+		t.setCtrl(s, ctrlDeleted)
+		t.deleted++
+	}
+	t.slots[s] = swissSlot[K, V]{}
+	t.size--
+}
+
+func (t *swissTable[K, V]) compute(key K, h uint64, f func(old V, exists bool) (V, bool)) (V, bool) {
+	var zero V
+	if s, ok := t.find(key, h); ok {
+		value, keep := f(t.slots[s].value, true)
+		if !keep {
+			t.removeSlot(s)
+			return zero, false
+		}
+		t.slots[s].value = value
+		return value, true
+	}
+
+	value, keep := f(zero, false)
+	if !keep {
+		return zero, false
+	}
+	s := t.findSlot(h)
+	if t.growthLeft == 0 && t.ctrlAt(s) == ctrlEmpty {
+		t.rehash()
+		s = t.findSlot(h)
+	}
+	t.set(s, key, h, value)
+	return value, true
+}
+
+func (t *swissTable[K, V]) removeFunc(f func(key K, value V) bool) {
+	// removeSlot only rewrites the slot's own control byte, so the scan can
+	// continue from the group's original word
+	for g, w := range t.ctrl {
+		for m := matchFull(w); m != 0; m &= m - 1 {
+			s := uint64(g)*groupSize + firstSlot(m)
+			if f(t.slots[s].key, t.slots[s].value) {
+				t.removeSlot(s)
+			}
+		}
+	}
+}
+
+func (t *swissTable[K, V]) len() int {
+	return t.size
+}
+
+func (t *swissTable[K, V]) capacity() int {
+	return len(t.slots)
+}
+
+func (t *swissTable[K, V]) forEach(f func(key K, value V) bool) {
+	for g, w := range t.ctrl {
+		for m := matchFull(w); m != 0; m &= m - 1 {
+			slot := &t.slots[uint64(g)*groupSize+firstSlot(m)]
+			if !f(slot.key, slot.value) {
+				return
+			}
+		}
+	}
+}
+
+func (t *swissTable[K, V]) clone() table[K, V] {
+	c := *t
+	c.ctrl = append([]uint64(nil), t.ctrl...)
+	c.slots = append([]swissSlot[K, V](nil), t.slots...)
+	return &c
+}
+
+func (t *swissTable[K, V]) reserve(n int) {
+	if n-t.size > t.growthLeft {
+		t.resize(max(groupsFor(n), len(t.ctrl)))
+	}
+}
+
+func (t *swissTable[K, V]) compact() {
+	if groups := groupsFor(t.size); groups < len(t.ctrl) || t.deleted > 0 {
+		t.resize(groups)
+	}
+}
+
+// rehash makes room for one more entry. When tombstones make up a large share
+// of the table, they are cleared in place; otherwise the table doubles.
+func (t *swissTable[K, V]) rehash() {
+	if len(t.slots) > groupSize && t.size*32 <= len(t.slots)*25 {
+		t.rehashInPlace()
+	} else {
+		t.resize(len(t.ctrl) * 2)
+	}
+}
+
+// resize moves every entry into a new table with the given number of groups
+func (t *swissTable[K, V]) resize(groups int) {
+	defer t.rehashed(time.Now())
+	oldCtrl, oldSlots := t.ctrl, t.slots
+	t.init(groups)
+	for g, w := range oldCtrl {
+		for m := matchFull(w); m != 0; m &= m - 1 {
+			slot := &oldSlots[uint64(g)*groupSize+firstSlot(m)]
+			h := t.hash(slot.key)
+			t.set(t.findSlot(h), slot.key, h, slot.value)
+		}
+	}
+}
+
+// rehashInPlace drops every tombstone without allocating. All full slots are
+// first marked deleted and all special slots empty; each marked entry is then
+// moved to the first free slot on its probe sequence, swapping with any
+// not-yet-processed entry found there.
+func (t *swissTable[K, V]) rehashInPlace() {
+	defer t.rehashed(time.Now())
+	for g, w := range t.ctrl {
+		var converted uint64
+		for i := uint64(0); i < groupSize; i++ {
+			c := uint64(ctrlEmpty)
+			if uint8(w>>(8*i))&0x80 == 0 {
+				c = ctrlDeleted
+			}
+			converted |= c << (8 * i)
+		}
+		t.ctrl[g] = converted
+	}
+
+	for i := uint64(0); i < uint64(len(t.slots)); i++ {
+		if t.ctrlAt(i) != ctrlDeleted {
+			continue
+		}
+		h := t.hash(t.slots[i].key)
+		target := t.findSlot(h)
+		if target/groupSize == i/groupSize {
+			// Already in the first group its probe sequence reaches
+			t.setCtrl(i, h2(h))
+			continue
+		}
+		if t.ctrlAt(target) == ctrlEmpty {
+			t.setCtrl(target, h2(h))
+			t.slots[target] = t.slots[i]
+			t.setCtrl(i, ctrlEmpty)
+			t.slots[i] = swissSlot[K, V]{}
+		} else {
+			// target holds an entry that has not been processed yet; swap
+			// and process the entry now in slot i
+			t.setCtrl(target, h2(h))
+			t.slots[target], t.slots[i] = t.slots[i], t.slots[target]
+			i--
+		}
+	}
+
+	t.deleted = 0
+	t.growthLeft = len(t.slots)*7/8 - t.size
+}
@@ -0,0 +1,106 @@
+package quickmap
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestSwissTable(t *testing.T) {
+	// Test the control byte matchers
+	t.Run("Matchers", func(t *testing.T) {
+		// bytes, low to high: 0x12, empty, deleted, 0x12, 0x55, empty, 0x00, 0x7f
+		w := uint64(0x7f00805512fe8012)
+		if got := matchH2(w, 0x12); got != 0x0000000080000080 {
+			t.Errorf("matchH2(0x12) = %#x, expected %#x", got, uint64(0x0000000080000080))
+		}
+		if got := matchEmpty(w); got != 0x0000800000008000 {
+			t.Errorf("matchEmpty = %#x, expected %#x", got, uint64(0x0000800000008000))
+		}
+		if got := matchEmptyOrDeleted(w); got != 0x0000800000808000 {
+			t.Errorf("matchEmptyOrDeleted = %#x, expected %#x", got, uint64(0x0000800000808000))
+		}
+		if got := matchFull(w); got != 0x8080008080000080 {
+			t.Errorf("matchFull = %#x, expected %#x", got, uint64(0x8080008080000080))
+		}
+	})
+
+	// Test that churn is absorbed by in-place rehashing rather than growth
+	t.Run("Tombstones", func(t *testing.T) {
+		// 780 live entries load a 1024-slot table just under the in-place
+		// rehash threshold of 25/32
+		m := NewMapWithCapacity[int, int](nil, 780, WithBackend(Swiss))
+		st := m.t.(*swissTable[int, int])
+		initialCap := st.capacity()
+		for i := 0; i < 100000; i++ {
+			m.Insert(i, i)
+			if i >= 780 {
+				m.Delete(i - 780)
+			}
+		}
+		if st.capacity() != initialCap {
+			t.Errorf("Capacity grew from %d to %d with at most 781 live entries", initialCap, st.capacity())
+		}
+		if m.Size() != 780 {
+			t.Errorf("Size() = %d, expected 780", m.Size())
+		}
+		for i := 100000 - 780; i < 100000; i++ {
+			if value, exists := m.Get(i); !exists || value != i {
+				t.Errorf("Get(%d) = %d, %t; expected %d, true", i, value, exists, i)
+			}
+		}
+		if _, exists := m.Get(100000 - 781); exists {
+			t.Errorf("Get(%d) returned true after deletion, expected false", 100000-781)
+		}
+	})
+
+	// Test rehashInPlace directly on a table full of tombstones
+	t.Run("Rehash in place", func(t *testing.T) {
+		m := NewMapWithCapacity[string, int](nil, 100, WithBackend(Swiss), WithSeed(1))
+		st := m.t.(*swissTable[string, int])
+		for i := 0; i < 100; i++ {
+			m.Insert(strconv.Itoa(i), i)
+		}
+		for i := 0; i < 100; i += 2 {
+			m.Delete(strconv.Itoa(i))
+		}
+		st.rehashInPlace()
+		if st.deleted != 0 || st.growthLeft != st.capacity()*7/8-50 {
+			t.Errorf("After rehashInPlace, deleted = %d, growthLeft = %d; expected 0, %d", st.deleted, st.growthLeft, st.capacity()*7/8-50)
+		}
+		for i := 0; i < 100; i++ {
+			_, exists := m.Get(strconv.Itoa(i))
+			if exists != (i%2 == 1) {
+				t.Errorf("Get(%q) returned %t after rehashInPlace, expected %t", strconv.Itoa(i), exists, i%2 == 1)
+			}
+		}
+	})
+
+	// Test random operations against the built-in map
+	t.Run("Random operations", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		m := NewMapWithCapacity[int, int](nil, 1, WithBackend(Swiss))
+		model := make(map[int]int)
+		for i := 0; i < 200000; i++ {
+			key := rng.Intn(5000)
+			switch rng.Intn(3) {
+			case 0, 1:
+				m.Insert(key, i)
+				model[key] = i
+			case 2:
+				m.Delete(key)
+				delete(model, key)
+			}
+		}
+		if m.Size() != len(model) {
+			t.Errorf("Size() = %d, expected %d", m.Size(), len(model))
+		}
+		for key := 0; key < 5000; key++ {
+			value, exists := m.Get(key)
+			expected, expectedExists := model[key]
+			if exists != expectedExists || value != expected {
+				t.Errorf("Get(%d) = %d, %t; expected %d, %t", key, value, exists, expected, expectedExists)
+			}
+		}
+	})
+}
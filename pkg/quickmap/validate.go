@@ -0,0 +1,188 @@
+package quickmap
+
+import "fmt"
+
+// Validate walks the whole table and checks its invariants: the size matches
+// the number of entries, every entry sits where its hash places it and no key
+// is stored twice. It returns the first violation found, or nil. Validate is
+// meant for tests; building with the quickmapdebug tag runs it after every
+// mutation and panics on a violation.
+func (m *QuickMap[K, V]) Validate() error {
+	if m.t == nil {
+		return nil
+	}
+	return m.t.validate(m.hash)
+}
+
+// checkInvariants panics if the map fails Validate. It does nothing unless
+// the package is built with the quickmapdebug tag.
+func (m *QuickMap[K, V]) checkInvariants() {
+	if !debug {
+		return
+	}
+	if err := m.Validate(); err != nil {
+		panic(err)
+	}
+}
+
+// chainValidator counts the nodes of a set of chains and checks that no key
+// appears twice
+type chainValidator[K comparable] struct {
+	seen  map[K]struct{}
+	count int
+}
+
+func (c *chainValidator[K]) add(key K) error {
+	if _, ok := c.seen[key]; ok {
+		return fmt.Errorf("quickmap: key %v is stored twice", key)
+	}
+	c.seen[key] = struct{}{}
+	c.count++
+	return nil
+}
+
+func (c *chainValidator[K]) checkSize(size int) error {
+	if c.count != size {
+		return fmt.Errorf("quickmap: table holds %d entries, but its size is %d", c.count, size)
+	}
+	return nil
+}
+
+func (t *chainTable[K, V]) validate(hash func(K) uint64) error {
+	c := chainValidator[K]{seen: make(map[K]struct{}, t.size)}
+	for i, bucket := range t.buckets {
+		for n := bucket; n != nil; n = n.next {
+			h := hash(n.key)
+			if index := int(h % uint64(len(t.buckets))); index != i {
+				return fmt.Errorf("quickmap: key %v is in bucket %d, expected %d", n.key, i, index)
+			}
+			if oldIndex := t.oldBucket(h); oldIndex >= 0 {
+				return fmt.Errorf("quickmap: key %v is in the new buckets, but old bucket %d is not yet migrated", n.key, oldIndex)
+			}
+			if err := c.add(n.key); err != nil {
+				return err
+			}
+		}
+	}
+	for i, bucket := range t.old {
+		if i < t.migrated {
+			if bucket != nil {
+				return fmt.Errorf("quickmap: old bucket %d is migrated but not empty", i)
+			}
+			continue
+		}
+		for n := bucket; n != nil; n = n.next {
+			if index := int(hash(n.key) % uint64(len(t.old))); index != i {
+				return fmt.Errorf("quickmap: key %v is in old bucket %d, expected %d", n.key, i, index)
+			}
+			if err := c.add(n.key); err != nil {
+				return err
+			}
+		}
+	}
+	return c.checkSize(t.size)
+}
+
+func (t *linkedTable[K, V]) validate(hash func(K) uint64) error {
+	c := chainValidator[K]{seen: make(map[K]struct{}, t.size)}
+	for i, bucket := range t.buckets {
+		for n := bucket; n != nil; n = n.next {
+			if index := int(hash(n.key) % uint64(len(t.buckets))); index != i {
+				return fmt.Errorf("quickmap: key %v is in bucket %d, expected %d", n.key, i, index)
+			}
+			if err := c.add(n.key); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.checkSize(t.size); err != nil {
+		return err
+	}
+
+	// Every node must also appear exactly once in the order list
+	listed := 0
+	var before *linkedNode[K, V]
+	for n := t.head; n != nil; n = n.after {
+		if n.before != before {
+			return fmt.Errorf("quickmap: order list is broken at key %v", n.key)
+		}
+		if *t.find(n.key, hash(n.key)) != n {
+			return fmt.Errorf("quickmap: key %v is in the order list but not in its bucket", n.key)
+		}
+		before = n
+		if listed++; listed > t.size {
+			return fmt.Errorf("quickmap: order list holds more than %d entries", t.size)
+		}
+	}
+	if before != t.tail {
+		return fmt.Errorf("quickmap: order list does not end at its tail")
+	}
+	if listed != t.size {
+		return fmt.Errorf("quickmap: order list holds %d entries, but the size is %d", listed, t.size)
+	}
+	return nil
+}
+
+func (t *swissTable[K, V]) validate(hash func(K) uint64) error {
+	if len(t.slots) != len(t.ctrl)*groupSize {
+		return fmt.Errorf("quickmap: %d slots for %d groups", len(t.slots), len(t.ctrl))
+	}
+	full, deleted := 0, 0
+	for s := range t.slots {
+		switch c := t.ctrlAt(uint64(s)); c {
+		case ctrlEmpty:
+		case ctrlDeleted:
+			deleted++
+		default:
+			key := t.slots[s].key
+			h := hash(key)
+			if c != h2(h) {
+				return fmt.Errorf("quickmap: key %v in slot %d has control byte %#x, expected %#x", key, s, c, h2(h))
+			}
+			// find stops at the first match, so a key stored twice, or out of
+			// reach of its probe sequence, resolves to some other slot
+			if found, ok := t.find(key, h); !ok || found != uint64(s) {
+				return fmt.Errorf("quickmap: key %v in slot %d is not found by its probe sequence", key, s)
+			}
+			full++
+		}
+	}
+	if full != t.size {
+		return fmt.Errorf("quickmap: table holds %d entries, but its size is %d", full, t.size)
+	}
+	if deleted != t.deleted {
+		return fmt.Errorf("quickmap: table holds %d tombstones, but counts %d", deleted, t.deleted)
+	}
+	if t.growthLeft+t.size+t.deleted != len(t.slots)*7/8 {
+		return fmt.Errorf("quickmap: growth left %d does not match %d entries and %d tombstones in %d slots", t.growthLeft, t.size, t.deleted, len(t.slots))
+	}
+	return nil
+}
+
+func (t *robinTable[K, V]) validate(hash func(K) uint64) error {
+	full := 0
+	for i := range t.hashes {
+		if t.hashes[i] == 0 {
+			continue
+		}
+		key := t.keys[i]
+		h := hash(key) | occupied
+		if t.hashes[i] != h {
+			return fmt.Errorf("quickmap: key %v in slot %d has a stale hash", key, i)
+		}
+		if found, ok := t.find(key, h); !ok || found != uint64(i) {
+			return fmt.Errorf("quickmap: key %v in slot %d is not found by its probe sequence", key, i)
+		}
+		// An entry can sit at most one slot further from home than the one
+		// before it, or an insert would have displaced it
+		next := (uint64(i) + 1) & t.mask
+		if t.hashes[next] != 0 && t.dist(next) > t.dist(uint64(i))+1 {
+			return fmt.Errorf("quickmap: slot %d is too far from its home slot", next)
+		}
+		full++
+	}
+	if full != t.size {
+		return fmt.Errorf("quickmap: table holds %d entries, but its size is %d", full, t.size)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package quickmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	// Test that a random mix of operations keeps every backend valid
+	t.Run("Backends", func(t *testing.T) {
+		configs := map[string][]Option{
+			"Chaining":    {WithBackend(Chaining)},
+			"Incremental": {WithIncrementalResize(2)},
+			"Swiss":       {WithBackend(Swiss)},
+			"RobinHood":   {WithBackend(RobinHood)},
+			"Shrinking":   {WithBackend(Swiss), WithShrinkFactor(0.25)},
+		}
+		for name, opts := range configs {
+			t.Run(name, func(t *testing.T) {
+				m := NewMapWithCapacity[int, int](nil, 4, opts...)
+				checkOperations(t, m)
+			})
+		}
+		t.Run("Linked", func(t *testing.T) {
+			m := NewLinkedMapWithCapacity[int, int](nil, 4)
+			checkOperations(t, &m.QuickMap)
+			for i := 0; i < 100; i++ {
+				m.MoveToFront(i)
+				m.MoveToBack(i * 3)
+			}
+			if err := m.Validate(); err != nil {
+				t.Errorf("Validate() after moves = %v, expected nil", err)
+			}
+		})
+	})
+
+	// Test that overwriting the last node of a chain does not count the key
+	// again
+	t.Run("Overwrite chain tail", func(t *testing.T) {
+		m := NewMapWithCapacity[int, int](func(key int, _ uint64) uint64 { return 0 }, 64)
+		for i := 0; i < 3; i++ {
+			m.Insert(i, i)
+		}
+		capacity := m.t.capacity()
+		for round := 0; round < 100; round++ {
+			for i := 0; i < 3; i++ {
+				m.Insert(i, round)
+			}
+		}
+		if m.Size() != 3 {
+			t.Errorf("Size() = %d after overwrites, expected 3", m.Size())
+		}
+		if m.t.capacity() != capacity {
+			t.Errorf("capacity() = %d after overwrites, expected %d", m.t.capacity(), capacity)
+		}
+		if err := m.Validate(); err != nil {
+			t.Errorf("Validate() = %v, expected nil", err)
+		}
+	})
+
+	// Test that Validate reports corrupted tables
+	t.Run("Corruption", func(t *testing.T) {
+		fill := func(opts ...Option) *QuickMap[int, int] {
+			m := NewMap[int, int](nil, opts...)
+			for i := 0; i < 100; i++ {
+				m.Insert(i, i)
+			}
+			return m
+		}
+		cases := map[string]func() *QuickMap[int, int]{
+			"Chaining size": func() *QuickMap[int, int] {
+				m := fill()
+				m.t.(*chainTable[int, int]).size++
+				return m
+			},
+			"Chaining duplicate": func() *QuickMap[int, int] {
+				m := fill()
+				ct := m.t.(*chainTable[int, int])
+				i := m.hash(7) % uint64(len(ct.buckets))
+				ct.buckets[i] = &node[int, int]{key: 7, next: ct.buckets[i]}
+				ct.size++
+				return m
+			},
+			"Chaining placement": func() *QuickMap[int, int] {
+				m := fill()
+				ct := m.t.(*chainTable[int, int])
+				i := (m.hash(1000) + 1) % uint64(len(ct.buckets))
+				ct.buckets[i] = &node[int, int]{key: 1000, next: ct.buckets[i]}
+				ct.size++
+				return m
+			},
+			"Swiss size": func() *QuickMap[int, int] {
+				m := fill(WithBackend(Swiss))
+				m.t.(*swissTable[int, int]).size--
+				return m
+			},
+			"Swiss control byte": func() *QuickMap[int, int] {
+				m := fill(WithBackend(Swiss))
+				st := m.t.(*swissTable[int, int])
+				s, _ := st.find(5, m.hash(5))
+				st.setCtrl(s, h2(m.hash(5))^1)
+				return m
+			},
+			"RobinHood hash": func() *QuickMap[int, int] {
+				m := fill(WithBackend(RobinHood))
+				rt := m.t.(*robinTable[int, int])
+				i, _ := rt.find(5, m.hash(5))
+				rt.keys[i] = 1000
+				return m
+			},
+			"Linked order": func() *QuickMap[int, int] {
+				m := NewLinkedMap[int, int](nil)
+				for i := 0; i < 100; i++ {
+					m.Insert(i, i)
+				}
+				lt := m.linked()
+				lt.head.after.after = nil
+				return &m.QuickMap
+			},
+		}
+		for name, corrupt := range cases {
+			t.Run(name, func(t *testing.T) {
+				if err := corrupt().Validate(); err == nil {
+					t.Errorf("Validate() = nil, expected an error")
+				}
+			})
+		}
+	})
+
+	// Test that a zero map is valid
+	t.Run("Zero map", func(t *testing.T) {
+		var m QuickMap[string, int]
+		if err := m.Validate(); err != nil {
+			t.Errorf("Validate() = %v, expected nil", err)
+		}
+	})
+}
+
+// checkOperations applies random operations to m, checking it against a
+// built-in map and validating it after each one
+func checkOperations(t *testing.T, m *QuickMap[int, int]) {
+	t.Helper()
+	r := rand.New(rand.NewSource(1))
+	expected := make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		key := r.Intn(500)
+		switch op := r.Intn(10); {
+		case op < 5:
+			m.Insert(key, i)
+			expected[key] = i
+		case op < 8:
+			m.Delete(key)
+			delete(expected, key)
+		case op < 9:
+			m.Compute(key, func(old int, exists bool) (int, bool) { return old + 1, !exists })
+			if _, ok := expected[key]; ok {
+				delete(expected, key)
+			} else {
+				expected[key] = 1
+			}
+		default:
+			m.Get(key)
+		}
+		if err := m.Validate(); err != nil {
+			t.Fatalf("Validate() = %v after %d operations", err, i+1)
+		}
+	}
+	if m.Size() != len(expected) {
+		t.Errorf("Size() = %d, expected %d", m.Size(), len(expected))
+	}
+	for key, value := range expected {
+		if got, ok := m.Get(key); !ok || got != value {
+			t.Errorf("Get(%d) = %d, %t; expected %d, true", key, got, ok, value)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package quickmetrics publishes the operation counters of named maps through
+// expvar and in the Prometheus text exposition format, using only the
+// standard library. Maps report to a quickmap.Metrics given to them with
+// quickmap.WithMetrics; registering it here under a name publishes it:
+//
+//	var sessionMetrics quickmap.Metrics
+//	quickmetrics.Register("sessions", &sessionMetrics)
+//	sessions := quickmap.NewMap[string, int](nil, quickmap.WithMetrics(&sessionMetrics))
+//	http.Handle("/metrics", quickmetrics.Handler())
+//
+// Maps built without WithMetrics are not counted and pay nothing for it.
+package quickmetrics
+
+import (
+	"bufio"
+	"bytes"
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/marpit19/goquickmap/internal/ioutil"
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// Registry holds Metrics under unique names. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*quickmap.Metrics
+}
+
+// NewRegistry creates and returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*quickmap.Metrics)}
+}
+
+// Default is the Registry behind the package-level functions. It is published
+// through expvar under the name "quickmap".
+var Default = NewRegistry()
+
+func init() {
+	expvar.Publish("quickmap", Default.Var())
+}
+
+// Register adds m to the registry under name. It returns an error if the
+// name is already taken.
+func (r *Registry) Register(name string, m *quickmap.Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		return fmt.Errorf("quickmetrics: %q is already registered", name)
+	}
+	r.metrics[name] = m
+	return nil
+}
+
+// Unregister removes the Metrics registered under name, if any
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.metrics, name)
+}
+
+// snapshots returns the names in the registry in order, and a snapshot of
+// each one's metrics
+func (r *Registry) snapshots() ([]string, []quickmap.MetricsSnapshot) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	snapshots := make([]quickmap.MetricsSnapshot, len(names))
+	for i, name := range names {
+		snapshots[i] = r.metrics[name].Snapshot()
+	}
+	return names, snapshots
+}
+
+// expvarMetrics is the JSON form of a snapshot in expvar
+type expvarMetrics struct {
+	Inserts    uint64  `json:"inserts"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	Deletes    uint64  `json:"deletes"`
+	Resizes    uint64  `json:"resizes"`
+	Size       int64   `json:"size"`
+	Buckets    int64   `json:"buckets"`
+	LoadFactor float64 `json:"load_factor"`
+}
+
+// Var returns an expvar.Var whose value is an object holding the metrics of
+// every registered name. Publish it with expvar.Publish; Default is published
+// already.
+func (r *Registry) Var() expvar.Var {
+	return expvar.Func(func() any {
+		names, snapshots := r.snapshots()
+		values := make(map[string]expvarMetrics, len(names))
+		for i, name := range names {
+			s := snapshots[i]
+			values[name] = expvarMetrics{s.Inserts, s.Hits, s.Misses, s.Deletes, s.Resizes, s.Size, s.Buckets, s.LoadFactor}
+		}
+		return values
+	})
+}
+
+// family is one Prometheus metric, with a sample for every registered name
+type family struct {
+	name, kind, help string
+	value            func(s quickmap.MetricsSnapshot) string
+}
+
+func formatUint(v uint64) string { return strconv.FormatUint(v, 10) }
+
+var families = []family{
+	{"quickmap_inserts_total", "counter", "Values stored by Insert, InsertMany and Compute.",
+		func(s quickmap.MetricsSnapshot) string { return formatUint(s.Inserts) }},
+	{"quickmap_hits_total", "counter", "Get calls that found their key.",
+		func(s quickmap.MetricsSnapshot) string { return formatUint(s.Hits) }},
+	{"quickmap_misses_total", "counter", "Get calls that did not find their key.",
+		func(s quickmap.MetricsSnapshot) string { return formatUint(s.Misses) }},
+	{"quickmap_deletes_total", "counter", "Entries removed.",
+		func(s quickmap.MetricsSnapshot) string { return formatUint(s.Deletes) }},
+	{"quickmap_resizes_total", "counter", "Table rebuilds.",
+		func(s quickmap.MetricsSnapshot) string { return formatUint(s.Resizes) }},
+	{"quickmap_size", "gauge", "Entries held.",
+		func(s quickmap.MetricsSnapshot) string { return strconv.FormatInt(s.Size, 10) }},
+	{"quickmap_buckets", "gauge", "Buckets or slots allocated.",
+		func(s quickmap.MetricsSnapshot) string { return strconv.FormatInt(s.Buckets, 10) }},
+	{"quickmap_load_factor", "gauge", "Entries per bucket or slot.",
+		func(s quickmap.MetricsSnapshot) string { return strconv.FormatFloat(s.LoadFactor, 'g', -1, 64) }},
+}
+
+// WriteTo writes the metrics of every registered name to w in the Prometheus
+// text exposition format, labelled with map="name", and returns the number of
+// bytes written. Every family's HELP and TYPE lines are written even when no
+// names are registered.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	names, snapshots := r.snapshots()
+	cw := &ioutil.CountingWriter{W: w}
+	bw := bufio.NewWriter(cw)
+	labels := make([]string, len(names))
+	for i, name := range names {
+		labels[i] = labelEscaper.Replace(name)
+	}
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		for i, s := range snapshots {
+			fmt.Fprintf(bw, "%s{map=\"%s\"} %s\n", f.name, labels[i], f.value(s))
+		}
+	}
+	err := bw.Flush()
+	return cw.N, err
+}
+
+// labelEscaper escapes a label value as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Handler returns an http.Handler that serves the registry's metrics in the
+// Prometheus text exposition format. The metrics are formatted in full
+// before the response is written, and an error writing it is logged with the
+// log package.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var buf bytes.Buffer
+		r.WriteTo(&buf)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+		if _, err := buf.WriteTo(w); err != nil {
+			log.Printf("quickmetrics: writing metrics: %v", err)
+		}
+	})
+}
+
+// Register adds m to Default under name
+func Register(name string, m *quickmap.Metrics) error {
+	return Default.Register(name, m)
+}
+
+// Unregister removes the Metrics registered in Default under name
+func Unregister(name string) {
+	Default.Unregister(name)
+}
+
+// Handler returns an http.Handler that serves the metrics in Default
+func Handler() http.Handler {
+	return Default.Handler()
+}
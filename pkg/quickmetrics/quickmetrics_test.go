@@ -0,0 +1,115 @@
+package quickmetrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+func TestRegistry(t *testing.T) {
+	newRegistry := func() (*Registry, *quickmap.QuickMap[string, int]) {
+		r := NewRegistry()
+		var metrics quickmap.Metrics
+		if err := r.Register(`users "eu"`, &metrics); err != nil {
+			t.Fatalf("Register returned %v, expected nil", err)
+		}
+		m := quickmap.NewMap[string, int](nil, quickmap.WithMetrics(&metrics))
+		m.Insert("a", 1)
+		m.Insert("b", 2)
+		m.Get("a")
+		m.Get("z")
+		m.Delete("b")
+		return r, m
+	}
+
+	// Test the Prometheus text exposition
+	t.Run("Prometheus", func(t *testing.T) {
+		r, _ := newRegistry()
+		rec := httptest.NewRecorder()
+		r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+			t.Errorf("Content-Type = %q, expected the text exposition format", ct)
+		}
+		body := rec.Body.String()
+		for _, line := range []string{
+			"# TYPE quickmap_inserts_total counter",
+			`quickmap_inserts_total{map="users \"eu\""} 2`,
+			`quickmap_hits_total{map="users \"eu\""} 1`,
+			`quickmap_misses_total{map="users \"eu\""} 1`,
+			`quickmap_deletes_total{map="users \"eu\""} 1`,
+			"# TYPE quickmap_size gauge",
+			`quickmap_size{map="users \"eu\""} 1`,
+			`quickmap_buckets{map="users \"eu\""} 16`,
+			`quickmap_load_factor{map="users \"eu\""} 0.0625`,
+		} {
+			if !strings.Contains(body, line+"\n") {
+				t.Errorf("exposition does not contain %q:\n%s", line, body)
+			}
+		}
+	})
+
+	// Test the expvar value
+	t.Run("Expvar", func(t *testing.T) {
+		r, _ := newRegistry()
+		var values map[string]map[string]float64
+		if err := json.Unmarshal([]byte(r.Var().String()), &values); err != nil {
+			t.Fatalf("Var().String() is not JSON: %v", err)
+		}
+		v := values[`users "eu"`]
+		if v["inserts"] != 2 || v["misses"] != 1 || v["size"] != 1 || v["load_factor"] != 0.0625 {
+			t.Errorf("Var() = %v, expected 2 inserts, 1 miss, size 1 and load factor 0.0625", v)
+		}
+		if expvar.Get("quickmap") == nil {
+			t.Errorf("Default registry is not published through expvar")
+		}
+	})
+
+	// Test that a failed write of the response is logged
+	t.Run("Handler write error", func(t *testing.T) {
+		r, _ := newRegistry()
+		var logged bytes.Buffer
+		log.SetOutput(&logged)
+		defer log.SetOutput(os.Stderr)
+		r.Handler().ServeHTTP(failingWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/metrics", nil))
+		if !strings.Contains(logged.String(), "quickmetrics: writing metrics: connection reset") {
+			t.Errorf("Handler logged %q, expected the write error", logged.String())
+		}
+	})
+
+	// Test duplicate names and Unregister
+	t.Run("Names", func(t *testing.T) {
+		r, _ := newRegistry()
+		if err := r.Register(`users "eu"`, new(quickmap.Metrics)); err == nil {
+			t.Errorf("Register of a taken name returned nil, expected an error")
+		}
+		r.Unregister(`users "eu"`)
+		var buf strings.Builder
+		if n, err := r.WriteTo(&buf); n != int64(buf.Len()) || err != nil {
+			t.Errorf("WriteTo = %d, %v after Unregister; expected %d, nil", n, err, buf.Len())
+		}
+		if out := buf.String(); !strings.Contains(out, "# TYPE quickmap_hits_total counter\n") || strings.Contains(out, "{map=") {
+			t.Errorf("WriteTo after Unregister wrote %q, expected the family headers without samples", out)
+		}
+		if err := r.Register(`users "eu"`, new(quickmap.Metrics)); err != nil {
+			t.Errorf("Register after Unregister returned %v, expected nil", err)
+		}
+	})
+}
+
+// failingWriter is a ResponseWriter whose writes fail
+type failingWriter struct {
+	http.ResponseWriter
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
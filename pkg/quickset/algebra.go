@@ -0,0 +1,141 @@
+package quickset
+
+// The set operations below iterate the smaller of the two sets wherever the
+// result allows it. Sets they return are configured like the receiver, with
+// the same hasher and options, and are ordered if the receiver is a
+// LinkedQuickSet.
+
+// empty returns an empty set configured like s
+func (s *QuickSet[T]) empty(initialCapacity int) *QuickSet[T] {
+	return newSet(s.newData, initialCapacity)
+}
+
+// smaller returns s and other ordered by size
+func (s *QuickSet[T]) smaller(other *QuickSet[T]) (small, large *QuickSet[T]) {
+	if other.Size() < s.Size() {
+		return other, s
+	}
+	return s, other
+}
+
+// Union returns a new set with the elements that are in s, other, or both
+func (s *QuickSet[T]) Union(other *QuickSet[T]) *QuickSet[T] {
+	result := s.empty(s.Size() + other.Size())
+	result.UnionWith(s)
+	result.UnionWith(other)
+	return result
+}
+
+// Intersect returns a new set with the elements that are in both s and other
+func (s *QuickSet[T]) Intersect(other *QuickSet[T]) *QuickSet[T] {
+	small, large := s.smaller(other)
+	result := s.empty(small.Size())
+	for element := range small.All() {
+		if large.Contains(element) {
+			result.Add(element)
+		}
+	}
+	return result
+}
+
+// Difference returns a new set with the elements of s that are not in other
+func (s *QuickSet[T]) Difference(other *QuickSet[T]) *QuickSet[T] {
+	result := s.empty(s.Size())
+	for element := range s.All() {
+		if !other.Contains(element) {
+			result.Add(element)
+		}
+	}
+	return result
+}
+
+// SymmetricDifference returns a new set with the elements that are in
+// exactly one of s and other
+func (s *QuickSet[T]) SymmetricDifference(other *QuickSet[T]) *QuickSet[T] {
+	result := s.Difference(other)
+	for element := range other.All() {
+		if !s.Contains(element) {
+			result.Add(element)
+		}
+	}
+	return result
+}
+
+// UnionWith adds every element of other to s
+func (s *QuickSet[T]) UnionWith(other *QuickSet[T]) {
+	for element := range other.All() {
+		s.Add(element)
+	}
+}
+
+// IntersectWith removes every element of s that is not in other
+func (s *QuickSet[T]) IntersectWith(other *QuickSet[T]) {
+	if s == other {
+		return
+	}
+	s.RetainFunc(other.Contains)
+}
+
+// DifferenceWith removes every element of other from s
+func (s *QuickSet[T]) DifferenceWith(other *QuickSet[T]) {
+	switch {
+	case s == other:
+		s.RetainFunc(func(T) bool { return false })
+	case other.Size() < s.Size():
+		for element := range other.All() {
+			s.Remove(element)
+		}
+	default:
+		s.RetainFunc(func(element T) bool { return !other.Contains(element) })
+	}
+}
+
+// SymmetricDifferenceWith removes the elements of other that are in s from
+// it, and adds those that are not
+func (s *QuickSet[T]) SymmetricDifferenceWith(other *QuickSet[T]) {
+	if s == other {
+		s.DifferenceWith(other)
+		return
+	}
+	for element := range other.All() {
+		if s.Contains(element) {
+			s.Remove(element)
+		} else {
+			s.Add(element)
+		}
+	}
+}
+
+// IsSubset reports whether every element of s is in other
+func (s *QuickSet[T]) IsSubset(other *QuickSet[T]) bool {
+	if s.Size() > other.Size() {
+		return false
+	}
+	for element := range s.All() {
+		if !other.Contains(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset reports whether every element of other is in s
+func (s *QuickSet[T]) IsSuperset(other *QuickSet[T]) bool {
+	return other.IsSubset(s)
+}
+
+// IsDisjoint reports whether s and other have no elements in common
+func (s *QuickSet[T]) IsDisjoint(other *QuickSet[T]) bool {
+	small, large := s.smaller(other)
+	for element := range small.All() {
+		if large.Contains(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether s and other contain the same elements
+func (s *QuickSet[T]) Equal(other *QuickSet[T]) bool {
+	return s.Size() == other.Size() && s.IsSubset(other)
+}
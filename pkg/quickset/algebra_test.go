@@ -0,0 +1,113 @@
+package quickset
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetAlgebra(t *testing.T) {
+	setOf := func(elements ...int) *QuickSet[int] {
+		s := NewSet[int](nil)
+		s.AddMany(elements)
+		return s
+	}
+	sorted := func(s *QuickSet[int]) []int {
+		return slices.Sorted(s.All())
+	}
+
+	a := setOf(1, 2, 3, 4)
+	b := setOf(3, 4, 5)
+
+	// Test the operations that return new sets
+	t.Run("New sets", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			result   *QuickSet[int]
+			expected []int
+		}{
+			{"Union", a.Union(b), []int{1, 2, 3, 4, 5}},
+			{"Intersect", a.Intersect(b), []int{3, 4}},
+			{"Intersect reversed", b.Intersect(a), []int{3, 4}},
+			{"Difference", a.Difference(b), []int{1, 2}},
+			{"Difference reversed", b.Difference(a), []int{5}},
+			{"SymmetricDifference", a.SymmetricDifference(b), []int{1, 2, 5}},
+			{"Union with itself", a.Union(a), []int{1, 2, 3, 4}},
+			{"Difference with itself", a.Difference(a), nil},
+		}
+		for _, c := range cases {
+			if got := sorted(c.result); !slices.Equal(got, c.expected) {
+				t.Errorf("%s = %v, expected %v", c.name, got, c.expected)
+			}
+		}
+		if got := sorted(a); !slices.Equal(got, []int{1, 2, 3, 4}) {
+			t.Errorf("Operations changed their receiver to %v", got)
+		}
+	})
+
+	// Test the in-place variants, from both the smaller and the larger side
+	t.Run("In place", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			apply    func(s, other *QuickSet[int])
+			s, other *QuickSet[int]
+			expected []int
+		}{
+			{"UnionWith", (*QuickSet[int]).UnionWith, setOf(1, 2), setOf(2, 3), []int{1, 2, 3}},
+			{"IntersectWith", (*QuickSet[int]).IntersectWith, setOf(1, 2, 3), setOf(2, 3, 4, 5), []int{2, 3}},
+			{"IntersectWith larger", (*QuickSet[int]).IntersectWith, setOf(1, 2, 3, 4, 5), setOf(2, 6), []int{2}},
+			{"DifferenceWith", (*QuickSet[int]).DifferenceWith, setOf(1, 2, 3), setOf(2, 3, 4, 5), []int{1}},
+			{"DifferenceWith smaller", (*QuickSet[int]).DifferenceWith, setOf(1, 2, 3, 4, 5), setOf(2, 6), []int{1, 3, 4, 5}},
+			{"SymmetricDifferenceWith", (*QuickSet[int]).SymmetricDifferenceWith, setOf(1, 2), setOf(2, 3), []int{1, 3}},
+		}
+		for _, c := range cases {
+			c.apply(c.s, c.other)
+			if got := sorted(c.s); !slices.Equal(got, c.expected) {
+				t.Errorf("%s left %v, expected %v", c.name, got, c.expected)
+			}
+		}
+
+		s := setOf(1, 2, 3)
+		s.IntersectWith(s)
+		s.UnionWith(s)
+		if got := sorted(s); !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("IntersectWith and UnionWith on the same set left %v, expected [1 2 3]", got)
+		}
+		s.SymmetricDifferenceWith(s)
+		if s.Size() != 0 {
+			t.Errorf("SymmetricDifferenceWith on the same set left %v, expected []", sorted(s))
+		}
+	})
+
+	// Test the comparisons
+	t.Run("Comparisons", func(t *testing.T) {
+		sub := setOf(3, 4)
+		if !sub.IsSubset(a) || !sub.IsSubset(b) || a.IsSubset(b) || !a.IsSubset(a) {
+			t.Errorf("IsSubset returned an unexpected result")
+		}
+		if !a.IsSuperset(sub) || sub.IsSuperset(a) {
+			t.Errorf("IsSuperset returned an unexpected result")
+		}
+		if a.IsDisjoint(b) || !setOf(1, 2).IsDisjoint(b) || !setOf().IsDisjoint(a) {
+			t.Errorf("IsDisjoint returned an unexpected result")
+		}
+		if !a.Equal(setOf(4, 3, 2, 1)) || a.Equal(b) || a.Equal(setOf(1, 2, 3, 5)) {
+			t.Errorf("Equal returned an unexpected result")
+		}
+	})
+
+	// Test that results keep the receiver's configuration
+	t.Run("Linked", func(t *testing.T) {
+		l := NewLinkedSet[int](nil)
+		l.AddMany([]int{9, 1, 8, 2})
+		if got := slices.Collect(l.Intersect(setOf(8, 9, 2, 100, 101)).All()); !slices.Equal(got, []int{9, 8, 2}) {
+			t.Errorf("Intersect on a LinkedQuickSet = %v, expected [9 8 2] in insertion order", got)
+		}
+		l.DifferenceWith(setOf(1))
+		if got := l.Elements(); !slices.Equal(got, []int{9, 8, 2}) {
+			t.Errorf("DifferenceWith on a LinkedQuickSet left %v, expected [9 8 2]", got)
+		}
+		if first, _ := l.First(); first != 9 {
+			t.Errorf("First() = %d, expected 9", first)
+		}
+	})
+}
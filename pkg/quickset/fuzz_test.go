@@ -0,0 +1,57 @@
+package quickset
+
+import (
+	"testing"
+
+	"github.com/marpit19/goquickmap/internal/maptest"
+)
+
+// FuzzQuickSet checks QuickSet against the built-in map
+func FuzzQuickSet(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 0, 2, 0, 1, 1, 2, 1, 1, 1})
+	f.Add([]byte{3, 4, 1, 0, 2, 0, 3, 0, 4, 0, 5, 4, 2, 1, 3, 5})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := maptest.CheckSet(data, setAdapter{NewSetWithCapacity[int](nil, 1)}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// setAdapter presents a QuickSet as a maptest.Map whose values are all 0
+type setAdapter struct {
+	s *QuickSet[int]
+}
+
+func (a setAdapter) Insert(key, _ int) {
+	a.s.Add(key)
+}
+
+func (a setAdapter) Get(key int) (int, bool) {
+	return 0, a.s.Contains(key)
+}
+
+func (a setAdapter) Delete(key int) {
+	a.s.Remove(key)
+}
+
+func (a setAdapter) InsertMany(pairs map[int]int) {
+	elements := make([]int, 0, len(pairs))
+	for key := range pairs {
+		elements = append(elements, key)
+	}
+	a.s.AddMany(elements)
+}
+
+func (a setAdapter) DeleteMany(keys []int) {
+	a.s.RemoveMany(keys)
+}
+
+func (a setAdapter) ForEach(f func(key, value int)) {
+	for element := range a.s.All() {
+		f(element, 0)
+	}
+}
+
+func (a setAdapter) Size() int {
+	return a.s.Size()
+}
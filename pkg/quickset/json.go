@@ -0,0 +1,32 @@
+package quickset
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalJSON encodes the set as a JSON array of its elements. The order is
+// unspecified unless the set was created with quickmap.WithSortedJSON; a
+// LinkedQuickSet encodes in insertion order.
+func (s *QuickSet[T]) MarshalJSON() ([]byte, error) {
+	if s.data == nil {
+		return []byte("[]"), nil
+	}
+	return s.data.MarshalJSON()
+}
+
+// UnmarshalJSON decodes a JSON array into the set, adding to the elements
+// already present. A zero QuickSet is first initialised as if by NewSet with
+// no hasher and no options.
+func (s *QuickSet[T]) UnmarshalJSON(data []byte) error {
+	return s.DecodeJSON(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeJSON reads the next JSON value from dec into the set, as described
+// for UnmarshalJSON, one element at a time
+func (s *QuickSet[T]) DecodeJSON(dec *json.Decoder) error {
+	if s.data == nil {
+		*s = *NewSet[T](nil)
+	}
+	return s.data.DecodeJSON(dec)
+}
@@ -0,0 +1,45 @@
+package quickset
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+func TestJSON(t *testing.T) {
+	// Test sorted output and a round trip through a zero set
+	t.Run("Round trip", func(t *testing.T) {
+		s := NewSet[string](nil, quickmap.WithSortedJSON())
+		s.AddMany([]string{"pear", "apple", "fig"})
+		data, err := json.Marshal(s)
+		if err != nil || string(data) != `["apple","fig","pear"]` {
+			t.Errorf("Marshal = %s, %v; expected [\"apple\",\"fig\",\"pear\"], nil", data, err)
+		}
+		var decoded QuickSet[string]
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal returned %v, expected nil", err)
+		}
+		if !decoded.Equal(s) {
+			t.Errorf("Elements() = %v after round trip, expected %v", decoded.Elements(), s.Elements())
+		}
+	})
+
+	// Test that a LinkedQuickSet encodes in insertion order
+	t.Run("Linked", func(t *testing.T) {
+		s := NewLinkedSet[int](nil)
+		s.AddMany([]int{3, 1, 2})
+		data, err := json.Marshal(s)
+		if err != nil || string(data) != `[3,1,2]` {
+			t.Errorf("Marshal = %s, %v; expected [3,1,2], nil", data, err)
+		}
+	})
+
+	// Test that an object is rejected
+	t.Run("Errors", func(t *testing.T) {
+		s := NewSet[int](nil)
+		if err := json.Unmarshal([]byte(`{"1":{}}`), s); err == nil {
+			t.Errorf("Unmarshal of an object returned nil, expected an error")
+		}
+	})
+}
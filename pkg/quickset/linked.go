@@ -0,0 +1,117 @@
+package quickset
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// LinkedQuickSet is a QuickSet that iterates in insertion order, backed by a
+// quickmap.LinkedQuickMap. Adding an element that is already present keeps
+// its position.
+type LinkedQuickSet[T comparable] struct {
+	QuickSet[T]
+	linked *quickmap.LinkedQuickMap[T, struct{}]
+}
+
+// LinkedStringSet is a LinkedQuickSet of strings, as returned by NewLinked
+type LinkedStringSet = LinkedQuickSet[string]
+
+// NewLinked creates and returns a new LinkedQuickSet of strings
+func NewLinked(opts ...quickmap.Option) *LinkedStringSet {
+	return NewLinkedSet[string](nil, opts...)
+}
+
+// NewLinkedSet creates and returns a new LinkedQuickSet that hashes its elements with hasher
+func NewLinkedSet[T comparable](hasher quickmap.Hasher[T], opts ...quickmap.Option) *LinkedQuickSet[T] {
+	return NewLinkedSetWithCapacity[T](hasher, 0, opts...)
+}
+
+// NewLinkedSetWithCapacity creates and returns a new LinkedQuickSet with the specified hasher and initial capacity
+func NewLinkedSetWithCapacity[T comparable](hasher quickmap.Hasher[T], initialCapacity int, opts ...quickmap.Option) *LinkedQuickSet[T] {
+	linked := quickmap.NewLinkedMapWithCapacity[T, struct{}](hasher, initialCapacity, opts...)
+	return &LinkedQuickSet[T]{
+		QuickSet: QuickSet[T]{
+			data: &linked.QuickMap,
+			newData: func(initialCapacity int) *quickmap.QuickMap[T, struct{}] {
+				return &quickmap.NewLinkedMapWithCapacity[T, struct{}](hasher, initialCapacity, opts...).QuickMap
+			},
+		},
+		linked: linked,
+	}
+}
+
+// initZero initialises a zero LinkedQuickSet as if by NewLinkedSet with no
+// hasher and no options, so that decoding into it keeps the order
+func (s *LinkedQuickSet[T]) initZero() {
+	if s.linked == nil {
+		*s = *NewLinkedSet[T](nil)
+	}
+}
+
+// UnmarshalJSON decodes a JSON array into the set, as described for
+// QuickSet.UnmarshalJSON, appending new elements in the order they appear. A
+// zero LinkedQuickSet is first initialised as if by NewLinkedSet with no
+// hasher and no options.
+func (s *LinkedQuickSet[T]) UnmarshalJSON(data []byte) error {
+	return s.DecodeJSON(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeJSON reads the next JSON value from dec into the set, as described
+// for UnmarshalJSON
+func (s *LinkedQuickSet[T]) DecodeJSON(dec *json.Decoder) error {
+	s.initZero()
+	return s.QuickSet.DecodeJSON(dec)
+}
+
+// ReadFrom replaces the elements of the set with a snapshot, as described
+// for QuickSet.ReadFrom, in the order the snapshot holds them. A zero
+// LinkedQuickSet is first initialised as if by NewLinkedSet with no hasher
+// and no options.
+func (s *LinkedQuickSet[T]) ReadFrom(r io.Reader) (int64, error) {
+	s.initZero()
+	return s.QuickSet.ReadFrom(r)
+}
+
+// AddMany inserts multiple elements into the set in the order they appear in elements
+func (s *LinkedQuickSet[T]) AddMany(elements []T) {
+	for _, element := range elements {
+		s.Add(element)
+	}
+}
+
+// MoveToFront makes element the first in iteration order, and reports whether it was present
+func (s *LinkedQuickSet[T]) MoveToFront(element T) bool {
+	return s.linked.MoveToFront(element)
+}
+
+// MoveToBack makes element the last in iteration order, and reports whether it was present
+func (s *LinkedQuickSet[T]) MoveToBack(element T) bool {
+	return s.linked.MoveToBack(element)
+}
+
+// First returns the first element in iteration order, or false if the set is empty
+func (s *LinkedQuickSet[T]) First() (T, bool) {
+	element, _, ok := s.linked.First()
+	return element, ok
+}
+
+// Last returns the last element in iteration order, or false if the set is empty
+func (s *LinkedQuickSet[T]) Last() (T, bool) {
+	element, _, ok := s.linked.Last()
+	return element, ok
+}
+
+// PopFirst removes and returns the first element in iteration order, or false if the set is empty
+func (s *LinkedQuickSet[T]) PopFirst() (T, bool) {
+	element, _, ok := s.linked.PopFirst()
+	return element, ok
+}
+
+// PopLast removes and returns the last element in iteration order, or false if the set is empty
+func (s *LinkedQuickSet[T]) PopLast() (T, bool) {
+	element, _, ok := s.linked.PopLast()
+	return element, ok
+}
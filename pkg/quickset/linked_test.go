@@ -0,0 +1,63 @@
+package quickset
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestLinkedQuickSet(t *testing.T) {
+	s := NewLinkedSet[int](nil)
+	s.AddMany([]int{5, 3, 8, 1})
+	s.Add(5)
+	if elements := slices.Collect(s.All()); !slices.Equal(elements, []int{5, 3, 8, 1}) {
+		t.Errorf("All() = %v, expected [5 3 8 1]", elements)
+	}
+	if !slices.Equal(s.Elements(), []int{5, 3, 8, 1}) {
+		t.Errorf("Elements() = %v, expected [5 3 8 1]", s.Elements())
+	}
+	s.MoveToFront(1)
+	s.MoveToBack(3)
+	if element, ok := s.PopFirst(); !ok || element != 1 {
+		t.Errorf("PopFirst() = %d, %t; expected 1, true", element, ok)
+	}
+	if element, ok := s.Last(); !ok || element != 3 {
+		t.Errorf("Last() = %d, %t; expected 3, true", element, ok)
+	}
+	if element, ok := s.PopLast(); !ok || element != 3 {
+		t.Errorf("PopLast() = %d, %t; expected 3, true", element, ok)
+	}
+	if element, ok := s.First(); !ok || element != 5 || s.Size() != 2 {
+		t.Errorf("First() = %d, %t with Size() = %d; expected 5, true, 2", element, ok, s.Size())
+	}
+
+	strings := NewLinked()
+	strings.AddMany([]string{"b", "a", "c"})
+	strings.Remove("a")
+	if elements := strings.Elements(); !slices.Equal(elements, []string{"b", "c"}) {
+		t.Errorf("Elements() = %v, expected [b c]", elements)
+	}
+}
+
+func TestLinkedQuickSetZeroValue(t *testing.T) {
+	var s LinkedQuickSet[string]
+	if err := s.UnmarshalJSON([]byte(`["b", "a"]`)); err != nil {
+		t.Fatalf("UnmarshalJSON returned %v", err)
+	}
+	if element, ok := s.First(); !ok || element != "b" {
+		t.Errorf("First() = %q, %t after UnmarshalJSON; expected \"b\", true", element, ok)
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo returned %v", err)
+	}
+	var read LinkedQuickSet[string]
+	if _, err := read.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom returned %v", err)
+	}
+	read.MoveToBack("b")
+	if elements := read.Elements(); !slices.Equal(elements, []string{"a", "b"}) {
+		t.Errorf("Elements() = %v after ReadFrom and MoveToBack, expected [a b]", elements)
+	}
+}
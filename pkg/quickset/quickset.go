@@ -1,67 +1,117 @@
 package quickset
 
 import (
+	"iter"
+
 	"github.com/marpit19/goquickmap/pkg/quickmap"
 )
 
 // QuickSet represents a set data structure
-type QuickSet struct {
-	data *quickmap.QuickMap
+type QuickSet[T comparable] struct {
+	data *quickmap.QuickMap[T, struct{}]
+	// newData creates an empty map configured like data, for the sets
+	// returned by Union and the other set operations
+	newData func(initialCapacity int) *quickmap.QuickMap[T, struct{}]
 }
 
-func New() *QuickSet {
-	return &QuickSet{
-		data: quickmap.New(),
-	}
+// StringSet is a QuickSet of strings, as returned by New
+type StringSet = QuickSet[string]
+
+func New(opts ...quickmap.Option) *StringSet {
+	return NewSetWithCapacity[string](nil, 0, opts...)
 }
 
 // NewWithCapacity creates and returns a new QuickSet with the specified initial capacity
-func NewWithCapacity(initialCapacity int) *QuickSet {
-	return &QuickSet{
-		data: quickmap.NewWithCapacity(initialCapacity),
+func NewWithCapacity(initialCapacity int, opts ...quickmap.Option) *StringSet {
+	return NewSetWithCapacity[string](nil, initialCapacity, opts...)
+}
+
+// NewSet creates and returns a new QuickSet that hashes its elements with hasher.
+// A nil hasher behaves as described for quickmap.NewMap.
+func NewSet[T comparable](hasher quickmap.Hasher[T], opts ...quickmap.Option) *QuickSet[T] {
+	return NewSetWithCapacity[T](hasher, 0, opts...)
+}
+
+// NewSetWithCapacity creates and returns a new QuickSet with the specified hasher and initial capacity
+func NewSetWithCapacity[T comparable](hasher quickmap.Hasher[T], initialCapacity int, opts ...quickmap.Option) *QuickSet[T] {
+	return newSet(func(initialCapacity int) *quickmap.QuickMap[T, struct{}] {
+		return quickmap.NewMapWithCapacity[T, struct{}](hasher, initialCapacity, opts...)
+	}, initialCapacity)
+}
+
+func newSet[T comparable](newData func(initialCapacity int) *quickmap.QuickMap[T, struct{}], initialCapacity int) *QuickSet[T] {
+	return &QuickSet[T]{
+		data:    newData(initialCapacity),
+		newData: newData,
 	}
 }
 
 // Add inserts an element into the set
-func (s *QuickSet) Add(element string) {
+func (s *QuickSet[T]) Add(element T) {
 	s.data.Insert(element, struct{}{})
 }
 
 // Contains checks if an element exists in the set
-func (s *QuickSet) Contains(element string) bool {
+func (s *QuickSet[T]) Contains(element T) bool {
 	_, exists := s.data.Get(element)
 	return exists
 }
 
 // Remove deletes an element from the set
-func (s *QuickSet) Remove(element string) {
+func (s *QuickSet[T]) Remove(element T) {
 	s.data.Delete(element)
 }
 
 // Size return sthe number of elements in the set
-func (s *QuickSet) Size() int {
+func (s *QuickSet[T]) Size() int {
 	return s.data.Size()
 }
 
+// All returns an iterator over the elements in the set. The set may be
+// modified during iteration; elements removed before they are reached are not
+// visited, as described for quickmap.QuickMap.All.
+func (s *QuickSet[T]) All() iter.Seq[T] {
+	return s.data.Keys()
+}
+
 // Elements return a slice of all elements in the set
-func (s *QuickSet) Elements() []string {
-	elements := make([]string, 0, s.Size())
-	s.data.ForEach(func(key string, value interface{}) {
+func (s *QuickSet[T]) Elements() []T {
+	elements := make([]T, 0, s.Size())
+	s.data.ForEach(func(key T, value struct{}) {
 		elements = append(elements, key)
 	})
 	return elements
 }
 
 // AddMany adds multiple elements to the set
-func (s *QuickSet) AddMany(elements []string) {
-	pairs := make(map[string]interface{}, len(elements))
+func (s *QuickSet[T]) AddMany(elements []T) {
+	pairs := make(map[T]struct{}, len(elements))
 	for _, elem := range elements {
 		pairs[elem] = struct{}{}
 	}
 	s.data.InsertMany(pairs)
 }
 
+// RetainFunc removes every element for which keep returns false, in a single
+// pass over the set
+func (s *QuickSet[T]) RetainFunc(keep func(element T) bool) {
+	s.data.DeleteFunc(func(key T, value struct{}) bool {
+		return !keep(key)
+	})
+}
+
 // RemoveMany removes multiple elements from the set
-func (s *QuickSet) RemoveMany(elements []string) {
+func (s *QuickSet[T]) RemoveMany(elements []T) {
 	s.data.DeleteMany(elements)
 }
+
+// Compact rebuilds the set at the smallest capacity that holds its current elements
+func (s *QuickSet[T]) Compact() {
+	s.data.Compact()
+}
+
+// Stats returns the statistics of the set's table, as described for
+// quickmap.QuickMap.Stats
+func (s *QuickSet[T]) Stats() quickmap.Stats {
+	return s.data.Stats()
+}
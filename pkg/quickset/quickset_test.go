@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
 )
 
 func TestQuickSet(t *testing.T) {
@@ -86,6 +88,88 @@ func TestQuickSet(t *testing.T) {
 	})
 }
 
+func TestQuickSetGeneric(t *testing.T) {
+	// Test integer elements with the default hasher
+	t.Run("Integer elements", func(t *testing.T) {
+		s := NewSetWithCapacity[int](nil, 8)
+		s.AddMany([]int{1, 2, 3, 2, 1})
+		if s.Size() != 3 {
+			t.Errorf("Size() = %d, expected 3", s.Size())
+		}
+		s.Remove(2)
+		if !s.Contains(1) || s.Contains(2) || !s.Contains(3) {
+			t.Errorf("After Remove(2), Elements() = %v, expected [1 3]", s.Elements())
+		}
+	})
+
+	// Test Compact
+	t.Run("Compact", func(t *testing.T) {
+		s := NewSet[int](nil)
+		for i := 0; i < 1000; i++ {
+			s.Add(i)
+		}
+		for i := 0; i < 990; i++ {
+			s.Remove(i)
+		}
+		s.Compact()
+		if s.Size() != 10 || !s.Contains(995) {
+			t.Errorf("After Compact, Elements() = %v, expected 990 to 999", s.Elements())
+		}
+	})
+
+	// Test Stats
+	t.Run("Stats", func(t *testing.T) {
+		s := NewSetWithCapacity[int](nil, 64)
+		s.AddMany([]int{1, 2, 3})
+		if stats := s.Stats(); stats.Size != 3 || stats.Buckets != 64 {
+			t.Errorf("Stats() = %d elements in %d buckets, expected 3 in 64", stats.Size, stats.Buckets)
+		}
+	})
+
+	// Test iterating with early exit
+	t.Run("All", func(t *testing.T) {
+		s := NewSet[int](nil)
+		s.AddMany([]int{1, 2, 3, 4, 5})
+		sum := 0
+		for element := range s.All() {
+			sum += element
+		}
+		if sum != 15 {
+			t.Errorf("Sum of All() = %d, expected 15", sum)
+		}
+		visited := 0
+		for range s.All() {
+			if visited++; visited == 2 {
+				break
+			}
+		}
+		if visited != 2 {
+			t.Errorf("All() visited %d elements before break, expected 2", visited)
+		}
+	})
+
+	// Test RetainFunc
+	t.Run("RetainFunc", func(t *testing.T) {
+		s := NewSet[int](nil)
+		for i := 0; i < 100; i++ {
+			s.Add(i)
+		}
+		s.RetainFunc(func(element int) bool { return element%4 == 0 })
+		if s.Size() != 25 || !s.Contains(96) || s.Contains(97) {
+			t.Errorf("After RetainFunc, Elements() = %v, expected multiples of 4", s.Elements())
+		}
+	})
+
+	// Test that map options reach the underlying QuickMap
+	t.Run("WithHashFunc", func(t *testing.T) {
+		s := NewWithCapacity(4, quickmap.WithHashFunc("wyhash"), quickmap.WithSeed(1))
+		s.AddMany([]string{"a", "b", "c", "d", "e"})
+		if s.Size() != 5 || !s.Contains("e") {
+			t.Errorf("After AddMany, Elements() = %v, expected 5 elements", s.Elements())
+		}
+	})
+}
+
 func BenchmarkQuickSet(b *testing.B) {
 	s := New()
 
@@ -0,0 +1,28 @@
+package quickset
+
+import (
+	"io"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+// WriteTo writes a snapshot of the set's elements to w, as described for
+// quickmap.QuickMap.WriteTo
+func (s *QuickSet[T]) WriteTo(w io.Writer) (int64, error) {
+	if s.data == nil {
+		var empty quickmap.QuickMap[T, struct{}]
+		return empty.WriteTo(w)
+	}
+	return s.data.WriteTo(w)
+}
+
+// ReadFrom replaces the elements of the set with a snapshot read from r, as
+// described for quickmap.QuickMap.ReadFrom. The set's table is allocated
+// once, at the size the snapshot records, up to a bound. A zero QuickSet is first
+// initialised as if by NewSet with no hasher and no options.
+func (s *QuickSet[T]) ReadFrom(r io.Reader) (int64, error) {
+	if s.data == nil {
+		*s = *NewSet[T](nil)
+	}
+	return s.data.ReadFrom(r)
+}
@@ -0,0 +1,43 @@
+package quickset
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/marpit19/goquickmap/pkg/quickmap"
+)
+
+func TestSnapshot(t *testing.T) {
+	// Test a round trip into a zero set
+	t.Run("Round trip", func(t *testing.T) {
+		s := NewSet[int](nil)
+		for i := 0; i < 5000; i++ {
+			s.Add(i * 3)
+		}
+		var buf bytes.Buffer
+		if _, err := s.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo returned %v, expected nil", err)
+		}
+		var loaded QuickSet[int]
+		if _, err := loaded.ReadFrom(&buf); err != nil {
+			t.Fatalf("ReadFrom returned %v, expected nil", err)
+		}
+		if !loaded.Equal(s) {
+			t.Errorf("Size() = %d after round trip, expected the same 5000 elements", loaded.Size())
+		}
+	})
+
+	// Test that a truncated snapshot is rejected
+	t.Run("Corruption", func(t *testing.T) {
+		s := NewLinkedSet[string](nil)
+		s.AddMany([]string{"a", "b", "c"})
+		var buf bytes.Buffer
+		s.WriteTo(&buf)
+		loaded := NewLinkedSet[string](nil)
+		_, err := loaded.ReadFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+		if !errors.Is(err, quickmap.ErrCorruptSnapshot) || loaded.Size() != 0 {
+			t.Errorf("ReadFrom of a truncated snapshot returned %v with Size() = %d, expected ErrCorruptSnapshot and 0", err, loaded.Size())
+		}
+	})
+}